@@ -539,6 +539,14 @@ func saveDirBlockInfo(transaction *btcutil.Tx, details *btcjson.BlockDetails) {
 	var saved = false
 	for _, dirBlockInfo := range dirBlockInfoMap {
 		if bytes.Compare(dirBlockInfo.BTCTxHash.Bytes(), transaction.Sha().Bytes()) == 0 {
+			// Read the height before the dirBlockInfo is confirmed, so that on a db
+			// error it stays in dirBlockInfoMap and is saved or re-anchored later
+			_, recordHeight, err := db.FetchBlockHeightCache()
+			if err != nil {
+				anchorLog.Error("Error in reading the directory block height, dirBlockInfo not saved: ", err.Error())
+				return
+			}
+
 			dirBlockInfo.BTCTxOffset = int32(details.Index)
 			dirBlockInfo.BTCBlockHeight = details.Height
 			btcBlockHash, _ := wire.NewShaHashFromStr(details.Hash)
@@ -553,7 +561,6 @@ func saveDirBlockInfo(transaction *btcutil.Tx, details *btcjson.BlockDetails) {
 			anchorRec.AnchorRecordVer = 1
 			anchorRec.DBHeight = dirBlockInfo.DBHeight
 			anchorRec.KeyMR = dirBlockInfo.DBMerkleRoot.String()
-			_, recordHeight, _ := db.FetchBlockHeightCache()
 			anchorRec.RecordHeight = uint32(recordHeight)
 			anchorRec.Bitcoin.Address = defaultAddress.String()
 			anchorRec.Bitcoin.TXID = transaction.Sha().String()
@@ -562,7 +569,7 @@ func saveDirBlockInfo(transaction *btcutil.Tx, details *btcjson.BlockDetails) {
 			anchorRec.Bitcoin.Offset = int32(details.Index)
 			anchorLog.Info("anchor.record saved: " + spew.Sdump(anchorRec))
 
-			err := submitEntryToAnchorChain(anchorRec)
+			err = submitEntryToAnchorChain(anchorRec)
 			if err != nil {
 				anchorLog.Error("Error in writing anchor into anchor chain: ", err.Error())
 			}
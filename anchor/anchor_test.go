@@ -4,10 +4,18 @@
 package anchor
 
 import (
+	"errors"
 	"fmt"
 	//"log"
 	//"reflect"
 	"testing"
+
+	"github.com/FactomProject/FactomCode/common"
+	"github.com/FactomProject/FactomCode/database"
+	factomwire "github.com/FactomProject/btcd/wire"
+	"github.com/btcsuitereleases/btcd/btcjson"
+	"github.com/btcsuitereleases/btcd/wire"
+	"github.com/btcsuitereleases/btcutil"
 	//"time"
 	//"github.com/FactomProject/FactomCode/common"
 	//"github.com/FactomProject/FactomCode/database"
@@ -29,6 +37,52 @@ func TestInitRPCClient(t *testing.T) {
 	}
 }
 
+// errHeightDb is a database.Db whose block height cache cannot be read.
+type errHeightDb struct {
+	database.Db
+	inserted int
+}
+
+func (d *errHeightDb) FetchBlockHeightCache() (*factomwire.ShaHash, int64, error) {
+	return nil, 0, errors.New("db error")
+}
+
+func (d *errHeightDb) InsertDirBlockInfo(dirBlockInfo *common.DirBlockInfo) error {
+	d.inserted++
+	return nil
+}
+
+func TestSaveDirBlockInfoHeightError(t *testing.T) {
+	defer func(d database.Db, m map[string]*common.DirBlockInfo) {
+		db, dirBlockInfoMap = d, m
+	}(db, dirBlockInfoMap)
+
+	fakeDb := new(errHeightDb)
+	db = fakeDb
+
+	tx := btcutil.NewTx(wire.NewMsgTx())
+	dirBlockInfo := new(common.DirBlockInfo)
+	dirBlockInfo.DBMerkleRoot = common.Sha([]byte("dblock"))
+	dirBlockInfo.BTCTxHash = toHash(tx.Sha())
+	dirBlockInfo.BTCBlockHash = common.NewHash()
+	dirBlockInfoMap = map[string]*common.DirBlockInfo{
+		dirBlockInfo.DBMerkleRoot.String(): dirBlockInfo,
+	}
+
+	saveDirBlockInfo(tx, &btcjson.BlockDetails{Height: 10, Index: 1})
+
+	// the dirBlockInfo stays unconfirmed so that it is saved later
+	if fakeDb.inserted != 0 {
+		t.Errorf("dirBlockInfo saved to db without a block height")
+	}
+	if dirBlockInfo.BTCConfirmed {
+		t.Errorf("dirBlockInfo confirmed without a block height")
+	}
+	if _, ok := dirBlockInfoMap[dirBlockInfo.DBMerkleRoot.String()]; !ok {
+		t.Errorf("dirBlockInfo removed from dirBlockInfoMap")
+	}
+}
+
 /*
 // maxTrials is the max attempts to writeToBTC
 const maxTrials = 3
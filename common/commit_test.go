@@ -99,3 +99,38 @@ func TestCommitChainMarshal(t *testing.T) {
 		t.Errorf("signature did not match after unmarshalbinary")
 	}
 }
+
+func TestCommitChainWeld(t *testing.T) {
+	fmt.Printf("---\nTestCommitChainWeld\n---\n")
+
+	chainID := common.NewHash()
+	p, _ := hex.DecodeString("dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd")
+	chainID.SetBytes(p)
+
+	cc := common.NewCommitChain()
+	p, _ = hex.DecodeString("cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc")
+	cc.EntryHash.SetBytes(p)
+	cc.ChainIDHash.SetBytes(common.DoubleSha(chainID.Bytes()))
+	cc.Weld.SetBytes(common.DoubleSha(append(cc.EntryHash.Bytes(), chainID.Bytes()...)))
+
+	if !cc.IsValidWeld(chainID) {
+		t.Errorf("valid weld was rejected")
+	}
+
+	// a weld over the ChainIDHash instead of the ChainID must be rejected
+	cc.Weld.SetBytes(common.DoubleSha(append(cc.EntryHash.Bytes(), cc.ChainIDHash.Bytes()...)))
+	if cc.IsValidWeld(chainID) {
+		t.Errorf("invalid weld was accepted")
+	}
+
+	// a correct weld with a wrong ChainIDHash must be rejected
+	cc.Weld.SetBytes(common.DoubleSha(append(cc.EntryHash.Bytes(), chainID.Bytes()...)))
+	cc.ChainIDHash.SetBytes(common.DoubleSha(cc.EntryHash.Bytes()))
+	if cc.IsValidWeld(chainID) {
+		t.Errorf("invalid chainid hash was accepted")
+	}
+
+	if cc.IsValidWeld(nil) {
+		t.Errorf("nil chainid was accepted")
+	}
+}
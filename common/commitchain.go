@@ -53,19 +53,19 @@ func NewCommitChain() *CommitChain {
 }
 
 func (e *CommitChain) Hash() *Hash {
-	bin, err := e.MarshalBinary()
+	h, err := ECBlockEntryHash(e)
 	if err != nil {
 		panic(err)
 	}
-	return Sha(bin)
+	return h
 }
 
 func (b *CommitChain) IsInterpretable() bool {
-	return false
+	return true
 }
 
 func (b *CommitChain) Interpret() string {
-	return ""
+	return fmt.Sprintf("CommitChain %s Credits %v", shortKey(b.ECPubKey), b.Credits)
 }
 
 // CommitMsg returns the binary marshaled message section of the CommitEntry
@@ -107,9 +107,26 @@ func (c *CommitChain) IsValid() bool {
 	return ed.VerifyCanonical(c.ECPubKey, c.CommitMsg(), c.Sig)
 }
 
+// IsValidWeld checks that the CommitChain.Weld is sha256(sha256(EntryHash +
+// ChainID)) for the ChainID of the revealed first entry, and that the
+// CommitChain.ChainIDHash is sha256(sha256(ChainID)).
+func (c *CommitChain) IsValidWeld(chainID *Hash) bool {
+	if chainID == nil {
+		return false
+	}
+
+	chainIDHash := DoubleSha(chainID.Bytes())
+	if !bytes.Equal(c.ChainIDHash.Bytes(), chainIDHash) {
+		return false
+	}
+
+	weld := DoubleSha(append(c.EntryHash.Bytes(), chainID.Bytes()...))
+	return bytes.Equal(c.Weld.Bytes(), weld)
+}
+
+// GetHash returns the same hash as Hash, the ECBlockEntryHash of the commit.
 func (c *CommitChain) GetHash() *Hash {
-	data, _ := c.MarshalBinary()
-	return Sha(data)
+	return c.Hash()
 }
 
 func (c *CommitChain) GetSigHash() *Hash {
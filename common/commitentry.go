@@ -49,19 +49,19 @@ func NewCommitEntry() *CommitEntry {
 }
 
 func (e *CommitEntry) Hash() *Hash {
-	bin, err := e.MarshalBinary()
+	h, err := ECBlockEntryHash(e)
 	if err != nil {
 		panic(err)
 	}
-	return Sha(bin)
+	return h
 }
 
 func (b *CommitEntry) IsInterpretable() bool {
-	return false
+	return true
 }
 
 func (b *CommitEntry) Interpret() string {
-	return ""
+	return fmt.Sprintf("CommitEntry %s Credits %v", shortKey(b.ECPubKey), b.Credits)
 }
 
 // CommitMsg returns the binary marshaled message section of the CommitEntry
@@ -102,9 +102,9 @@ func (c *CommitEntry) IsValid() bool {
 	return ed.VerifyCanonical(c.ECPubKey, c.CommitMsg(), c.Sig)
 }
 
+// GetHash returns the same hash as Hash, the ECBlockEntryHash of the commit.
 func (c *CommitEntry) GetHash() *Hash {
-	h, _ := c.MarshalBinary()
-	return Sha(h)
+	return c.Hash()
 }
 
 func (c *CommitEntry) GetSigHash() *Hash {
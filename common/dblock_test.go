@@ -196,6 +196,164 @@ func TestMakeSureBlockCountIsNotDuplicates(t *testing.T) {
 	}
 }
 
+func TestCreateDBlockRequiresSealedParent(t *testing.T) {
+	fmt.Println("\n---\nTestCreateDBlockRequiresSealedParent\n---\n")
+
+	chain := NewDChain()
+	chain.NextDBHeight = 2
+
+	prev := createTestDirectoryBlock()
+	if _, err := CreateDBlock(chain, prev, 10); err == nil {
+		t.Errorf("CreateDBlock accepted an unsealed parent block")
+	}
+
+	if err := prev.Seal(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	b, err := CreateDBlock(chain, prev, 10)
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	} else if !b.Header.PrevKeyMR.IsSameAs(prev.KeyMR) {
+		t.Errorf("PrevKeyMR does not match the KeyMR of the parent block")
+	}
+}
+
+func TestCreateDBlockHeightConsistency(t *testing.T) {
+	fmt.Println("\n---\nTestCreateDBlockHeightConsistency\n---\n")
+
+	prev := createTestDirectoryBlock()
+	if err := prev.Seal(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	chain := NewDChain()
+	chain.NextDBHeight = prev.Header.DBHeight + 1
+	b, err := CreateDBlock(chain, prev, 10)
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	} else if b.Header.DBHeight != prev.Header.DBHeight+1 {
+		t.Errorf("New block height %v does not follow the parent height %v", b.Header.DBHeight, prev.Header.DBHeight)
+	}
+
+	for _, next := range []uint32{prev.Header.DBHeight, prev.Header.DBHeight + 2} {
+		chain.NextDBHeight = next
+		if _, err := CreateDBlock(chain, prev, 10); err == nil {
+			t.Errorf("CreateDBlock accepted NextDBHeight %v after parent height %v", next, prev.Header.DBHeight)
+		}
+	}
+}
+
+func TestDirectoryBlockBuildHeader(t *testing.T) {
+	fmt.Println("\n---\nTestDirectoryBlockBuildHeader\n---\n")
+
+	chain := NewDChain()
+	b, err := CreateDBlock(chain, nil, 10)
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		de := new(DBEntry)
+		de.ChainID = NewHash()
+		de.KeyMR = NewHash()
+		de.KeyMR.SetBytes(bytes.Repeat([]byte{byte(i + 1)}, 32))
+		b.DBEntries = append(b.DBEntries, de)
+	}
+
+	if err := b.BuildHeader(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	p, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	b2 := new(DirectoryBlock)
+	if err := b2.UnmarshalBinary(p); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if len(b2.DBEntries) != 3 {
+		t.Errorf("Unmarshalled %v DBEntries, expected 3", len(b2.DBEntries))
+	}
+	mr, _ := b2.BuildBodyMR()
+	if !b2.Header.BodyMR.IsSameAs(mr) {
+		t.Errorf("Serialized BodyMR %s does not match the recomputed %s", b2.Header.BodyMR, mr)
+	}
+}
+
+func TestDirectoryBlockSeal(t *testing.T) {
+	fmt.Println("\n---\nTestDirectoryBlockSeal\n---\n")
+
+	chain := NewDChain()
+	b := chain.NextBlock
+	if err := chain.AddDBEntry(&DBEntry{ChainID: NewHash(), KeyMR: NewHash()}); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+
+	if err := b.Seal(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if !b.IsSealed {
+		t.Errorf("Seal did not mark the block sealed")
+	}
+	if b.Header.BlockCount != 1 {
+		t.Errorf("BlockCount is %v, expected 1", b.Header.BlockCount)
+	}
+	if h, _ := CreateHash(b); !b.DBHash.IsSameAs(h) {
+		t.Errorf("DBHash %s does not match the block hash %s", b.DBHash, h)
+	}
+	if b.KeyMR == nil || b.KeyMR.IsSameAs(NewHash()) {
+		t.Errorf("Seal did not build the KeyMR")
+	}
+
+	// double seal
+	if err := b.Seal(); err == nil {
+		t.Errorf("Sealing a sealed block did not fail")
+	}
+
+	// add after seal
+	if err := chain.AddDBEntry(&DBEntry{ChainID: NewHash(), KeyMR: NewHash()}); err == nil {
+		t.Errorf("Adding a DBEntry to a sealed block did not fail")
+	}
+	if len(b.DBEntries) != 1 {
+		t.Errorf("Sealed block has %v DBEntries, expected 1", len(b.DBEntries))
+	}
+
+	// no header
+	if err := new(DirectoryBlock).Seal(); err == nil {
+		t.Errorf("Sealing a block without a header did not fail")
+	}
+}
+
+func TestBuildKeyMerkleRootRequiresBodyMR(t *testing.T) {
+	fmt.Println("\n---\nTestBuildKeyMerkleRootRequiresBodyMR\n---\n")
+
+	b := createTestDirectoryBlock()
+
+	b.Header.BodyMR = nil
+	if err := b.BuildKeyMerkleRoot(); err == nil {
+		t.Errorf("KeyMR was built without a BodyMR")
+	}
+
+	// the zeroed placeholder from NewDBlockHeader
+	b.Header.BodyMR = NewHash()
+	if err := b.BuildKeyMerkleRoot(); err == nil {
+		t.Errorf("KeyMR was built over a zeroed BodyMR")
+	}
+	if b.KeyMR != nil {
+		t.Errorf("KeyMR was set by a failed build")
+	}
+
+	if err := b.BuildHeader(); err != nil {
+		t.Fatalf("Error: %v", err)
+	}
+	if err := b.BuildKeyMerkleRoot(); err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	if b.KeyMR == nil {
+		t.Errorf("KeyMR was not set")
+	}
+}
+
 func createTestDirectoryBlock() *DirectoryBlock {
 	dblock := new(DirectoryBlock)
 
@@ -426,6 +426,10 @@ func CreateDBlock(chain *DChain, prev *DirectoryBlock, cap uint) (b *DirectoryBl
 		return nil, errors.New("Previous block cannot be nil")
 	} else if prev != nil && chain.NextDBHeight == 0 {
 		return nil, errors.New("Origin block cannot have a parent block")
+	} else if prev != nil && !prev.IsSealed {
+		return nil, errors.New("Previous block must be sealed before it is used as a parent block")
+	} else if prev != nil && prev.Header.DBHeight+1 != chain.NextDBHeight {
+		return nil, fmt.Errorf("Chain NextDBHeight %v does not follow the previous block height %v", chain.NextDBHeight, prev.Header.DBHeight)
 	}
 
 	b = new(DirectoryBlock)
@@ -439,7 +443,9 @@ func CreateDBlock(chain *DChain, prev *DirectoryBlock, cap uint) (b *DirectoryBl
 	} else {
 		b.Header.PrevLedgerKeyMR, err = CreateHash(prev)
 		if prev.KeyMR == nil {
-			prev.BuildKeyMerkleRoot()
+			if err = prev.BuildKeyMerkleRoot(); err != nil {
+				return nil, err
+			}
 		}
 		b.Header.PrevKeyMR = prev.KeyMR
 	}
@@ -452,6 +458,8 @@ func CreateDBlock(chain *DChain, prev *DirectoryBlock, cap uint) (b *DirectoryBl
 	return b, err
 }
 
+var errSealedDBlock = errors.New("Cannot add a DBEntry to a sealed directory block")
+
 // Add DBEntry from an Entry Block
 func (c *DChain) AddEBlockToDBEntry(eb *EBlock) (err error) {
 
@@ -460,8 +468,11 @@ func (c *DChain) AddEBlockToDBEntry(eb *EBlock) (err error) {
 		return err
 	}
 	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+	if c.NextBlock.IsSealed {
+		return errSealedDBlock
+	}
 	c.NextBlock.DBEntries = append(c.NextBlock.DBEntries, dbEntry)
-	c.BlockMutex.Unlock()
 
 	return nil
 }
@@ -479,9 +490,12 @@ func (c *DChain) AddECBlockToDBEntry(ecb *ECBlock) (err error) {
 	}
 
 	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+	if c.NextBlock.IsSealed {
+		return errSealedDBlock
+	}
 	// Cblock is always at the first entry
 	c.NextBlock.DBEntries[1] = dbEntry // First three entries are ABlock, CBlock, FBlock
-	c.BlockMutex.Unlock()
 
 	return nil
 }
@@ -501,10 +515,13 @@ func (c *DChain) AddABlockToDBEntry(b *AdminBlock) (err error) {
 	}
 
 	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+	if c.NextBlock.IsSealed {
+		return errSealedDBlock
+	}
 	// Ablock is always at the first entry
 	// First three entries are ABlock, CBlock, FBlock
 	c.NextBlock.DBEntries[0] = dbEntry
-	c.BlockMutex.Unlock()
 
 	return nil
 }
@@ -524,10 +541,13 @@ func (c *DChain) AddFBlockToDBEntry(b block.IFBlock) (err error) {
 	}
 
 	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+	if c.NextBlock.IsSealed {
+		return errSealedDBlock
+	}
 	// Ablock is always at the first entry
 	// First three entries are ABlock, CBlock, FBlock
 	c.NextBlock.DBEntries[2] = dbEntry
-	c.BlockMutex.Unlock()
 
 	return nil
 }
@@ -536,8 +556,11 @@ func (c *DChain) AddFBlockToDBEntry(b block.IFBlock) (err error) {
 func (c *DChain) AddDBEntry(dbEntry *DBEntry) (err error) {
 
 	c.BlockMutex.Lock()
+	defer c.BlockMutex.Unlock()
+	if c.NextBlock.IsSealed {
+		return errSealedDBlock
+	}
 	c.NextBlock.DBEntries = append(c.NextBlock.DBEntries, dbEntry)
-	c.BlockMutex.Unlock()
 
 	return nil
 }
@@ -613,6 +636,39 @@ func (b *DirectoryBlock) MarshalBinary() (data []byte, err error) {
 	return buf.Bytes(), err
 }
 
+// BuildHeader sets the BodyMR and BlockCount in the header from the current
+// DBEntries, so that the serialized header matches the body a receiver sees.
+func (b *DirectoryBlock) BuildHeader() (err error) {
+	b.Header.BlockCount = uint32(len(b.DBEntries))
+	b.Header.BodyMR, err = b.BuildBodyMR()
+	return
+}
+
+// Seal finalizes the directory block. It builds the header from the DBEntries,
+// computes the DBHash and KeyMR, and marks the block sealed so that no more
+// DBEntries can be added to it.
+func (b *DirectoryBlock) Seal() (err error) {
+	if b.IsSealed {
+		return errors.New("Directory block is already sealed")
+	}
+	if b.Header == nil {
+		return errors.New("Directory block has no header")
+	}
+
+	if err = b.BuildHeader(); err != nil {
+		return
+	}
+	if b.DBHash, err = CreateHash(b); err != nil {
+		return
+	}
+	if err = b.BuildKeyMerkleRoot(); err != nil {
+		return
+	}
+	b.IsSealed = true
+
+	return nil
+}
+
 func (b *DirectoryBlock) BuildBodyMR() (mr *Hash, err error) {
 	hashes := make([]*Hash, len(b.DBEntries))
 	for i, entry := range b.DBEntries {
@@ -628,7 +684,13 @@ func (b *DirectoryBlock) BuildBodyMR() (mr *Hash, err error) {
 	return merkle[len(merkle)-1], nil
 }
 
+// BuildKeyMerkleRoot sets the KeyMR from the hash of the header and the
+// BodyMR. The BodyMR must already be built, see BuildHeader, so that the KeyMR
+// is never computed over an unset or zeroed placeholder.
 func (b *DirectoryBlock) BuildKeyMerkleRoot() (err error) {
+	if b.Header.BodyMR == nil || bytes.Equal(b.Header.BodyMR.Bytes(), ZERO_HASH) {
+		return errors.New("Cannot build the KeyMR of a directory block before its BodyMR is set")
+	}
 
 	// Create the Entry Block Key Merkle Root from the hash of Header and the Body Merkle Root
 	hashes := make([]*Hash, 0, 2)
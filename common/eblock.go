@@ -112,6 +112,13 @@ func (e *EBlock) KeyMR() (*Hash, error) {
 	return Sha(append(h.Bytes(), e.Header.BodyMR.Bytes()...)), nil
 }
 
+// MerkleProof returns the proof that the hash at entryIndex in the Entry Block
+// Body is included in the Body Merkle Root. It is verified with
+// VerifyMerkleProof, using the EntryCount of the header as the leaf count.
+func (e *EBlock) MerkleProof(entryIndex int) ([]*Hash, error) {
+	return BuildMerkleProof(e.Body.EBEntries, entryIndex)
+}
+
 // MarshalBinary returns the serialized binary form of the Entry Block.
 func (e *EBlock) MarshalBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
@@ -307,8 +314,13 @@ func NewEBlockBody() *EBlockBody {
 
 // MR calculates the Merkle Root of the Entry Block Body. See func
 // BuildMerkleTreeStore(hashes []*Hash) (merkles []*Hash) in common/merkle.go.
+// The Merkle Root of an empty body is Sha(nil).
 func (e *EBlockBody) MR() *Hash {
-	mrs := BuildMerkleTreeStore(e.EBEntries)
+	hashes := e.EBEntries
+	if len(hashes) == 0 {
+		hashes = []*Hash{Sha(nil)}
+	}
+	mrs := BuildMerkleTreeStore(hashes)
 	r := mrs[len(mrs)-1]
 	return r
 }
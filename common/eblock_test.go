@@ -47,3 +47,56 @@ func TestEBlockMarshal(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestEBlockMerkleProof(t *testing.T) {
+	t.Logf("\n---\nTestEBlockMerkleProof\n---\n")
+
+	// odd sizes exercise the nodes that are hashed with themselves
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		eb := common.NewEBlock()
+		for i := 0; i < n; i++ {
+			h := common.NewHash()
+			h.SetBytes(byteof(byte(i + 1)))
+			eb.Body.EBEntries = append(eb.Body.EBEntries, h)
+		}
+		root := eb.Body.MR()
+
+		for i, h := range eb.Body.EBEntries {
+			proof, err := eb.MerkleProof(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !common.VerifyMerkleProof(h, i, n, proof, root) {
+				t.Errorf("%v entries: proof for entry %v did not verify", n, i)
+			}
+			// including index n, where the proof of the last leaf of an
+			// odd level would otherwise match the root
+			for j := 0; j <= n; j++ {
+				if j != i && common.VerifyMerkleProof(h, j, n, proof, root) {
+					t.Errorf("%v entries: proof for entry %v verified at index %v", n, i, j)
+				}
+			}
+
+			// tamper with the proof
+			if len(proof) > 0 {
+				bad := common.NewHash()
+				bad.SetBytes(byteof(0xff))
+				proof[len(proof)-1] = bad
+				if common.VerifyMerkleProof(h, i, n, proof, root) {
+					t.Errorf("%v entries: tampered proof for entry %v verified", n, i)
+				}
+			}
+		}
+
+		if _, err := eb.MerkleProof(n); err == nil {
+			t.Errorf("%v entries: out of range index was accepted", n)
+		}
+	}
+}
+
+func TestEBlockBodyMREmpty(t *testing.T) {
+	b := common.NewEBlockBody()
+	if mr := b.MR(); !mr.IsSameAs(common.Sha(nil)) {
+		t.Errorf("empty body has Merkle Root %s, expected %s", mr, common.Sha(nil))
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// ApplyBalances updates the entry credit balances, keyed by the string of the
+// EC public key, with the commits and balance increases in the ECBlock. It
+// returns an error if a commit spends more credits than its key holds, or if a
+// balance increase would overflow the int32 balance.
+func (e *ECBlock) ApplyBalances(balances map[string]int32) error {
+	return e.ForEachEntry(func(entry ECBlockEntry) error {
+		switch entry.ECID() {
+		case ECIDChainCommit:
+			c := entry.(*CommitChain)
+			return spendCredits(balances, c.ECPubKey, int32(c.Credits), e.Header.EBHeight)
+		case ECIDEntryCommit:
+			c := entry.(*CommitEntry)
+			return spendCredits(balances, c.ECPubKey, int32(c.Credits), e.Header.EBHeight)
+		case ECIDBalanceIncrease:
+			b := entry.(*IncreaseBalance)
+			return addCredits(balances, b.ECPubKey, b.NumEC, e.Header.EBHeight)
+		case ECIDServerIndexNumber:
+		case ECIDMinuteNumber:
+		default:
+			return fmt.Errorf("Unknown entry type %v in ECBlock %v", entry.ECID(), e.Header.EBHeight)
+		}
+		return nil
+	})
+}
+
+// CreditSchedule is one version of the entry credit limits of entry and chain
+// commits. It applies to the ECBlocks from its ActivationHeight up to the
+// ActivationHeight of the next version.
+type CreditSchedule struct {
+	Version          byte
+	ActivationHeight uint32
+	MinEntryCredits  uint8
+	MaxEntryCredits  uint8
+	MinChainCredits  uint8
+	MaxChainCredits  uint8
+}
+
+// CREDIT_SCHEDULE_1_HEIGHT is the ECBlock height where the commit minimums of
+// version 1 take effect. It is not scheduled yet, so until then the minimums
+// are only a local policy: a server rejects an underpriced commit it is sent,
+// but the blocks it accepts are validated with version 0. Once every server
+// runs with that policy, this is to be set to a height past the last block
+// that may hold an underpriced commit, from which the blocks are validated
+// with version 1 as well.
+const CREDIT_SCHEDULE_1_HEIGHT = uint32(math.MaxUint32)
+
+// creditSchedules lists the versions of the credit limits in order of
+// activation height. Version 0 has applied since the genesis block and only
+// checks the maximums. Version 1 adds the minimums: 1 credit per entry, and 11
+// per chain, 10 for the chain plus its first entry. They are consensus rules,
+// so they are only read through CreditScheduleAt and LatestCreditSchedule.
+var creditSchedules = []CreditSchedule{
+	{
+		Version:          0,
+		ActivationHeight: 0,
+		MinEntryCredits:  0,
+		MaxEntryCredits:  MAX_ENTRY_CREDITS,
+		MinChainCredits:  0,
+		MaxChainCredits:  MAX_CHAIN_CREDITS,
+	},
+	{
+		Version:          1,
+		ActivationHeight: CREDIT_SCHEDULE_1_HEIGHT,
+		MinEntryCredits:  1,
+		MaxEntryCredits:  MAX_ENTRY_CREDITS,
+		MinChainCredits:  11,
+		MaxChainCredits:  MAX_CHAIN_CREDITS,
+	},
+}
+
+// CreditScheduleAt returns the credit schedule that applies to the ECBlock at
+// height.
+func CreditScheduleAt(height uint32) CreditSchedule {
+	s := creditSchedules[0]
+	for _, v := range creditSchedules {
+		if v.ActivationHeight > height {
+			break
+		}
+		s = v
+	}
+	return s
+}
+
+// LatestCreditSchedule returns the newest credit schedule, whether or not it
+// has taken effect yet.
+func LatestCreditSchedule() CreditSchedule {
+	return creditSchedules[len(creditSchedules)-1]
+}
+
+// ValidateCommitCredits checks that every commit in the ECBlock pays at least
+// the minimum and at most the maximum number of credits for its type, in the
+// credit schedule that applies at the height of the block.
+func (e *ECBlock) ValidateCommitCredits() error {
+	s := CreditScheduleAt(e.Header.EBHeight)
+	return e.ForEachEntry(func(entry ECBlockEntry) error {
+		switch entry.ECID() {
+		case ECIDChainCommit:
+			c := entry.(*CommitChain)
+			if c.Credits < s.MinChainCredits || c.Credits > s.MaxChainCredits {
+				return fmt.Errorf("CommitChain %s in ECBlock %v pays %v credits, must be %v to %v in credit schedule %v",
+					c.EntryHash.String(), e.Header.EBHeight, c.Credits, s.MinChainCredits, s.MaxChainCredits, s.Version)
+			}
+		case ECIDEntryCommit:
+			c := entry.(*CommitEntry)
+			if c.Credits < s.MinEntryCredits || c.Credits > s.MaxEntryCredits {
+				return fmt.Errorf("CommitEntry %s in ECBlock %v pays %v credits, must be %v to %v in credit schedule %v",
+					c.EntryHash.String(), e.Header.EBHeight, c.Credits, s.MinEntryCredits, s.MaxEntryCredits, s.Version)
+			}
+		}
+		return nil
+	})
+}
+
+// AddECCredits adds numEC credits to the entry credit balance of pub in
+// balances. It returns an error, and leaves the balance unchanged, if the
+// result does not fit in the int32 balance.
+func AddECCredits(balances map[string]int32, pub *[32]byte, numEC uint64) error {
+	key := string(pub[:])
+	if numEC > math.MaxInt32 || int64(balances[key])+int64(numEC) > math.MaxInt32 {
+		return fmt.Errorf("Adding %v credits overflows the balance %v of %x", numEC, balances[key], pub[:])
+	}
+	balances[key] += int32(numEC)
+	return nil
+}
+
+// SubECCredits takes credits from the entry credit balance of pub in balances,
+// without checking that the balance covers them. It returns an error, and
+// leaves the balance unchanged, if the result does not fit in the int32
+// balance.
+func SubECCredits(balances map[string]int32, pub *[32]byte, credits uint64) error {
+	key := string(pub[:])
+	if credits > math.MaxInt32 || int64(balances[key])-int64(credits) < math.MinInt32 {
+		return fmt.Errorf("Taking %v credits overflows the balance %v of %x", credits, balances[key], pub[:])
+	}
+	balances[key] -= int32(credits)
+	return nil
+}
+
+func addCredits(balances map[string]int32, pub *[32]byte, numEC uint64, height uint32) error {
+	if err := AddECCredits(balances, pub, numEC); err != nil {
+		return fmt.Errorf("IncreaseBalance in ECBlock %v: %v", height, err)
+	}
+	return nil
+}
+
+func spendCredits(balances map[string]int32, pub *[32]byte, credits int32, height uint32) error {
+	key := string(pub[:])
+	if balances[key] < credits {
+		return fmt.Errorf("Commit in ECBlock %v spends %v credits but %x only has %v",
+			height, credits, pub[:], balances[key])
+	}
+	return SubECCredits(balances, pub, uint64(credits))
+}
+
+// ECBalancesAt replays the ECBlocks from the genesis block up to and including
+// the block at height, and returns the resulting entry credit balance of each
+// EC public key. The blocks must be sorted by height without any gaps.
+func ECBalancesAt(blocks []ECBlock, height uint32) (map[string]int32, error) {
+	balances := make(map[string]int32)
+	for i := range blocks {
+		b := &blocks[i]
+		if b.Header.EBHeight != uint32(i) {
+			return nil, fmt.Errorf("ECBlock at index %v has height %v", i, b.Header.EBHeight)
+		}
+		if err := b.ApplyBalances(balances); err != nil {
+			return nil, err
+		}
+		if b.Header.EBHeight == height {
+			return balances, nil
+		}
+	}
+	return nil, fmt.Errorf("ECBlock height %v not reached, only %v blocks found", height, len(blocks))
+}
+
+// CreditStats returns the entry credits bought and spent in the ECBlocks with
+// heights from through to. The blocks must be sorted by height, and every
+// height in the range must be present. The totals are int64 so that they do
+// not wrap over a long range, while each IncreaseBalance must still fit in an
+// int32 balance.
+func CreditStats(blocks []ECBlock, from, to uint32) (bought, spent int64, err error) {
+	if from > to {
+		return 0, 0, fmt.Errorf("Invalid ECBlock height range %v-%v", from, to)
+	}
+
+	var count uint32
+	for i := range blocks {
+		b := &blocks[i]
+		if b.Header.EBHeight < from || b.Header.EBHeight > to {
+			continue
+		}
+		if b.Header.EBHeight != from+count {
+			return 0, 0, fmt.Errorf("ECBlock height %v is missing from range %v-%v", from+count, from, to)
+		}
+		err = b.ForEachEntry(func(entry ECBlockEntry) error {
+			switch entry.ECID() {
+			case ECIDChainCommit:
+				spent += int64(entry.(*CommitChain).Credits)
+			case ECIDEntryCommit:
+				spent += int64(entry.(*CommitEntry).Credits)
+			case ECIDBalanceIncrease:
+				numEC := entry.(*IncreaseBalance).NumEC
+				if numEC > math.MaxInt32 {
+					return fmt.Errorf("IncreaseBalance of %v credits in ECBlock %v is out of range", numEC, b.Header.EBHeight)
+				}
+				bought += int64(numEC)
+			case ECIDServerIndexNumber:
+			case ECIDMinuteNumber:
+			default:
+				return fmt.Errorf("Unknown entry type %v in ECBlock %v", entry.ECID(), b.Header.EBHeight)
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		count++
+	}
+	if count != to-from+1 {
+		return 0, 0, fmt.Errorf("ECBlock height %v is missing from range %v-%v", from+count, from, to)
+	}
+	return bought, spent, nil
+}
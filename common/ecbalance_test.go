@@ -0,0 +1,252 @@
+package common_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/FactomProject/FactomCode/common"
+)
+
+func newTestBalanceBlocks() []common.ECBlock {
+	keyA := new([32]byte)
+	copy(keyA[:], byteof(0xaa))
+	keyB := new([32]byte)
+	copy(keyB[:], byteof(0xbb))
+
+	blocks := make([]common.ECBlock, 3)
+	for i := range blocks {
+		blocks[i] = *common.NewECBlock()
+		blocks[i].Header.EBHeight = uint32(i)
+		blocks[i].AddEntry(common.NewServerIndexNumber())
+	}
+
+	// block 0: A buys 100 credits
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = keyA
+	ib.NumEC = 100
+	blocks[0].AddEntry(ib)
+
+	// block 1: A commits a chain and an entry, B buys 5 credits
+	cc := common.NewCommitChain()
+	cc.ECPubKey = keyA
+	cc.Credits = 11
+	blocks[1].AddEntry(cc)
+	m := common.NewMinuteNumber()
+	m.Number = 1
+	blocks[1].AddEntry(m)
+	ce := common.NewCommitEntry()
+	ce.ECPubKey = keyA
+	ce.Credits = 1
+	blocks[1].AddEntry(ce)
+	ib = common.NewIncreaseBalance()
+	ib.ECPubKey = keyB
+	ib.NumEC = 5
+	blocks[1].AddEntry(ib)
+
+	// block 2: B commits an entry it cannot pay for
+	ce = common.NewCommitEntry()
+	ce.ECPubKey = keyB
+	ce.Credits = 6
+	blocks[2].AddEntry(ce)
+
+	return blocks
+}
+
+func TestECBalancesAt(t *testing.T) {
+	blocks := newTestBalanceBlocks()
+	a := string(byteof(0xaa))
+	b := string(byteof(0xbb))
+
+	balances, err := common.ECBalancesAt(blocks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[a] != 100 {
+		t.Errorf("wrong balances at height 0: %v", balances)
+	}
+
+	balances, err = common.ECBalancesAt(blocks, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 || balances[a] != 88 || balances[b] != 5 {
+		t.Errorf("wrong balances at height 1: %v", balances)
+	}
+
+	// B overspends in block 2
+	if _, err := common.ECBalancesAt(blocks, 2); err == nil {
+		t.Errorf("overspend in block 2 was not detected")
+	}
+
+	// the target height is never reached
+	if _, err := common.ECBalancesAt(blocks[:2], 2); err == nil {
+		t.Errorf("missing height was not detected")
+	}
+
+	// a gap in the block heights
+	blocks[1].Header.EBHeight = 5
+	if _, err := common.ECBalancesAt(blocks, 1); err == nil {
+		t.Errorf("gap in block heights was not detected")
+	}
+}
+
+func TestCreditStats(t *testing.T) {
+	blocks := newTestBalanceBlocks()
+
+	bought, spent, err := common.CreditStats(blocks, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bought != 105 || spent != 18 {
+		t.Errorf("wrong stats for heights 0-2: bought %v spent %v", bought, spent)
+	}
+
+	bought, spent, err = common.CreditStats(blocks, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bought != 5 || spent != 12 {
+		t.Errorf("wrong stats for height 1: bought %v spent %v", bought, spent)
+	}
+
+	if _, _, err := common.CreditStats(blocks, 2, 1); err == nil {
+		t.Errorf("reversed range was not rejected")
+	}
+
+	// the range extends past the last block
+	if _, _, err := common.CreditStats(blocks, 1, 3); err == nil {
+		t.Errorf("missing block at the end of the range was not detected")
+	}
+
+	// a block missing inside the range
+	gap := []common.ECBlock{blocks[0], blocks[2]}
+	if _, _, err := common.CreditStats(gap, 0, 2); err == nil {
+		t.Errorf("missing block inside the range was not detected")
+	}
+}
+
+func TestCreditScheduleAt(t *testing.T) {
+	if s := common.CreditScheduleAt(0); s.Version != 0 || s.MinEntryCredits != 0 || s.MinChainCredits != 0 {
+		t.Errorf("genesis block has credit schedule %+v", s)
+	}
+	if s := common.LatestCreditSchedule(); s.MinEntryCredits != 1 || s.MinChainCredits != 11 {
+		t.Errorf("unexpected latest credit schedule %+v", s)
+	}
+	schedules := common.CreditSchedules()
+	for i := 1; i < len(schedules); i++ {
+		if schedules[i].ActivationHeight < schedules[i-1].ActivationHeight {
+			t.Errorf("credit schedule %v activates before the schedule before it", i)
+		}
+	}
+}
+
+func TestValidateCommitCredits(t *testing.T) {
+	defer common.SetCreditSchedules([]common.CreditSchedule{
+		{Version: 0, ActivationHeight: 0, MaxEntryCredits: 10, MaxChainCredits: 20},
+		{Version: 1, ActivationHeight: 100, MinEntryCredits: 1, MaxEntryCredits: 10, MinChainCredits: 11, MaxChainCredits: 20},
+	})()
+
+	for _, b := range newTestBalanceBlocks() {
+		if err := b.ValidateCommitCredits(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	cc := common.NewCommitChain()
+	cc.Credits = 10
+	ce := common.NewCommitEntry()
+	ce.Credits = 0
+	for _, c := range []common.ECBlockEntry{cc, ce} {
+		ecb := common.NewECBlock()
+		ecb.AddEntry(c)
+
+		// underpriced commits are valid before the minimums take effect
+		ecb.Header.EBHeight = 99
+		if err := ecb.ValidateCommitCredits(); err != nil {
+			t.Errorf("ECID %v below the minimum rejected before the minimums: %v", c.ECID(), err)
+		}
+
+		ecb.Header.EBHeight = 100
+		if err := ecb.ValidateCommitCredits(); err == nil {
+			t.Errorf("ECID %v below the minimum accepted", c.ECID())
+		}
+	}
+
+	// an overpriced CommitEntry
+	ce.Credits = 11
+	ecb := common.NewECBlock()
+	ecb.AddEntry(ce)
+	if err := ecb.ValidateCommitCredits(); err == nil {
+		t.Errorf("CommitEntry with %v credits was accepted", ce.Credits)
+	}
+}
+
+func TestCreditOverflow(t *testing.T) {
+	key := new([32]byte)
+	copy(key[:], byteof(0xaa))
+
+	newBlock := func(height uint32, numEC ...uint64) common.ECBlock {
+		b := common.NewECBlock()
+		b.Header.EBHeight = height
+		for _, n := range numEC {
+			ib := common.NewIncreaseBalance()
+			ib.ECPubKey = key
+			ib.NumEC = n
+			b.AddEntry(ib)
+		}
+		return *b
+	}
+
+	// a balance may reach but not pass math.MaxInt32
+	b := newBlock(0, math.MaxInt32-1, 1)
+	balances := make(map[string]int32)
+	if err := b.ApplyBalances(balances); err != nil {
+		t.Fatal(err)
+	}
+	if balances[string(key[:])] != math.MaxInt32 {
+		t.Errorf("balance is %v, expected %v", balances[string(key[:])], math.MaxInt32)
+	}
+	b = newBlock(1, 1)
+	if err := b.ApplyBalances(balances); err == nil {
+		t.Errorf("balance overflow was not detected")
+	}
+	if balances[string(key[:])] != math.MaxInt32 {
+		t.Errorf("balance changed by a failed increase: %v", balances[string(key[:])])
+	}
+
+	// the helpers the processor uses on its balance table
+	balances = map[string]int32{string(key[:]): math.MaxInt32 - 1}
+	if err := common.AddECCredits(balances, key, 2); err == nil {
+		t.Errorf("AddECCredits overflow was not detected")
+	}
+	if err := common.AddECCredits(balances, key, 1); err != nil {
+		t.Error(err)
+	}
+	balances[string(key[:])] = math.MinInt32 + 1
+	if err := common.SubECCredits(balances, key, 2); err == nil {
+		t.Errorf("SubECCredits overflow was not detected")
+	}
+	if balances[string(key[:])] != math.MinInt32+1 {
+		t.Errorf("balance changed by a failed SubECCredits: %v", balances[string(key[:])])
+	}
+
+	// a single increase larger than an int32
+	b = newBlock(0, math.MaxInt32+1)
+	if err := b.ApplyBalances(make(map[string]int32)); err == nil {
+		t.Errorf("out of range increase was not detected")
+	}
+
+	// totals over a range add up past math.MaxInt32 without wrapping
+	blocks := []common.ECBlock{
+		newBlock(0, math.MaxInt32),
+		newBlock(1, math.MaxInt32),
+		newBlock(2, math.MaxInt32),
+	}
+	bought, _, err := common.CreditStats(blocks, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bought != 3*math.MaxInt32 {
+		t.Errorf("bought %v, expected %v", bought, int64(3*math.MaxInt32))
+	}
+}
@@ -7,6 +7,7 @@ package common
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -61,6 +62,78 @@ func (e *ECBlock) AddEntry(entries ...ECBlockEntry) {
 	e.Body.Entries = append(e.Body.Entries, entries...)
 }
 
+// AddUniqueEntry adds a commit or balance increase to the ECBlock unless an
+// identical entry, by ECBlockEntryHash, is already in the block, so that a
+// retried submission is only recorded once. It returns false if the entry was
+// a duplicate and was not added. Minute and server markers may legitimately
+// repeat and are always added. The entries already in the body are hashed on
+// each call, so the result holds however Body.Entries was filled.
+func (e *ECBlock) AddUniqueEntry(entry ECBlockEntry) (bool, error) {
+	switch entry.ECID() {
+	case ECIDChainCommit, ECIDEntryCommit, ECIDBalanceIncrease:
+	default:
+		e.AddEntry(entry)
+		return true, nil
+	}
+
+	h, err := ECBlockEntryHash(entry)
+	if err != nil {
+		return false, err
+	}
+	dup := false
+	err = e.ForEachEntry(func(v ECBlockEntry) error {
+		if v.ECID() != entry.ECID() {
+			return nil
+		}
+		vh, err := ECBlockEntryHash(v)
+		if err != nil {
+			return err
+		}
+		if vh.IsSameAs(h) {
+			dup = true
+			return errDuplicateEntry
+		}
+		return nil
+	})
+	if dup {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	e.AddEntry(entry)
+	return true, nil
+}
+
+// errDuplicateEntry stops ForEachEntry in AddUniqueEntry once a duplicate is
+// found.
+var errDuplicateEntry = errors.New("duplicate entry")
+
+// ForEachEntry calls fn for each entry in the ECBlock body in order, skipping
+// nil entries, and stops at the first error returned by fn.
+func (e *ECBlock) ForEachEntry(fn func(ECBlockEntry) error) error {
+	for _, entry := range e.Body.Entries {
+		if entry == nil {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachIncreaseBalance calls fn for each IncreaseBalance in the ECBlock body
+// and stops at the first error returned by fn.
+func (e *ECBlock) ForEachIncreaseBalance(fn func(*IncreaseBalance) error) error {
+	return e.ForEachEntry(func(entry ECBlockEntry) error {
+		if b, ok := entry.(*IncreaseBalance); ok {
+			return fn(b)
+		}
+		return nil
+	})
+}
+
 func (e *ECBlock) Hash() (*Hash, error) {
 	p, err := e.MarshalBinary()
 	if err != nil {
@@ -108,7 +181,7 @@ func (e *ECBlock) BuildHeader() error {
 	}
 
 	e.Header.BodyHash = Sha(p)
-	e.Header.ObjectCount = uint64(len(e.Body.Entries))
+	e.Header.ObjectCount = uint64(e.entryCount())
 	e.Header.BodySize = uint64(len(p))
 
 	return nil
@@ -135,19 +208,31 @@ func (e *ECBlock) UnmarshalBinary(data []byte) (err error) {
 	return
 }
 
+// entryCount returns the number of entries in the ECBlock body, not counting
+// nil entries, which are not marshaled.
+func (e *ECBlock) entryCount() int {
+	n := 0
+	e.ForEachEntry(func(ECBlockEntry) error {
+		n++
+		return nil
+	})
+	return n
+}
+
 func (e *ECBlock) marshalBodyBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
 
-	for _, v := range e.Body.Entries {
+	err := e.ForEachEntry(func(v ECBlockEntry) error {
 		p, err := v.MarshalBinary()
 		if err != nil {
-			return buf.Bytes(), err
+			return err
 		}
 		buf.WriteByte(v.ECID())
 		buf.Write(p)
-	}
+		return nil
+	})
 
-	return buf.Bytes(), nil
+	return buf.Bytes(), err
 }
 
 func (e *ECBlock) marshalHeaderBinary() ([]byte, error) {
@@ -349,6 +434,29 @@ func (e *ECBlock) Spew() string {
 	return Spew(e)
 }
 
+// Summary returns a short human readable summary of the ECBlock with one line
+// per entry, skipping nil entries. Use Spew or JSONString for the full detail.
+func (e *ECBlock) Summary() string {
+	var out bytes.Buffer
+	out.WriteString(fmt.Sprintf("ECBlock %v Entries %v\n", e.Header.EBHeight, e.entryCount()))
+	out.WriteString(fmt.Sprintf("  PrevHeaderHash  %s\n", e.Header.PrevHeaderHash.String()))
+	out.WriteString(fmt.Sprintf("  PrevLedgerKeyMR %s\n", e.Header.PrevLedgerKeyMR.String()))
+	e.ForEachEntry(func(entry ECBlockEntry) error {
+		out.WriteString(fmt.Sprintf("  %s\n", entry.Interpret()))
+		return nil
+	})
+	return out.String()
+}
+
+// shortKey returns the first 4 bytes of an Entry Credit public key in hex for
+// use in the interpreted form of the ECBlockEntries.
+func shortKey(k *[32]byte) string {
+	if k == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%x...", k[:4])
+}
+
 type ECBlockBody struct {
 	Entries []ECBlockEntry
 }
@@ -384,9 +492,25 @@ type ECBlockEntry interface {
 	ECID() byte
 	MarshalBinary() ([]byte, error)
 	UnmarshalBinary(data []byte) error
+	// Hash returns the ECBlockEntryHash of the entry and panics if the entry
+	// cannot be marshaled.
 	Hash() *Hash
 }
 
+// ECBlockEntryHash returns the canonical hash that identifies an entry of an
+// ECBlock: the sha256 of the ECID followed by the binary entry, which are the
+// bytes the entry occupies in the ECBlock body. Since the ECID is included, it
+// never matches the hash of an entry of a different type with the same binary
+// form, such as a MinuteNumber and a ServerIndexNumber. The Hash method of
+// every ECBlockEntry returns it.
+func ECBlockEntryHash(e ECBlockEntry) (*Hash, error) {
+	p, err := e.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return Sha(append([]byte{e.ECID()}, p...)), nil
+}
+
 type ECBlockHeader struct {
 	ECChainID           *Hash
 	BodyHash            *Hash
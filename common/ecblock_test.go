@@ -2,6 +2,7 @@ package common_test
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -92,3 +93,252 @@ func byteof(b byte) []byte {
 	}
 	return r
 }
+
+func TestECBlockSummary(t *testing.T) {
+	ecb := common.NewECBlock()
+	ecb.Header.PrevHeaderHash.SetBytes(byteof(0x33))
+	ecb.Header.PrevLedgerKeyMR.SetBytes(byteof(0x44))
+	ecb.Header.EBHeight = 10
+
+	si := common.NewServerIndexNumber()
+	si.Number = 3
+	ecb.AddEntry(si)
+
+	cc := common.NewCommitChain()
+	copy(cc.ECPubKey[:], byteof(0xaa))
+	cc.Credits = 11
+	ecb.AddEntry(cc)
+
+	ce := common.NewCommitEntry()
+	copy(ce.ECPubKey[:], byteof(0xbb))
+	ce.Credits = 1
+	ecb.AddEntry(ce)
+
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = new([32]byte)
+	copy(ib.ECPubKey[:], byteof(0xcc))
+	ib.NumEC = 13
+	ecb.AddEntry(ib)
+
+	m := common.NewMinuteNumber()
+	m.Number = 1
+	ecb.AddEntry(m)
+
+	expected := "ECBlock 10 Entries 5\n" +
+		"  PrevHeaderHash  3333333333333333333333333333333333333333333333333333333333333333\n" +
+		"  PrevLedgerKeyMR 4444444444444444444444444444444444444444444444444444444444444444\n" +
+		"  ServerIndexNumber 3\n" +
+		"  CommitChain aaaaaaaa... Credits 11\n" +
+		"  CommitEntry bbbbbbbb... Credits 1\n" +
+		"  IncreaseBalance cccccccc... NumEC 13\n" +
+		"  MinuteNumber 1\n"
+
+	if s := ecb.Summary(); s != expected {
+		t.Errorf("unexpected ECBlock summary:\n%s\nexpected:\n%s", s, expected)
+	}
+
+	// spew prints a fmt.Stringer instead of its fields, so Spew would lose the
+	// full detail if the summary were the String method
+	if _, ok := interface{}(ecb).(fmt.Stringer); ok {
+		t.Errorf("ECBlock implements fmt.Stringer")
+	}
+
+	// nil entries are skipped
+	ecb.Body.Entries = append(ecb.Body.Entries, nil)
+	if s := ecb.Summary(); s != expected {
+		t.Errorf("unexpected ECBlock summary with a nil entry:\n%s\nexpected:\n%s", s, expected)
+	}
+
+	// an IncreaseBalance without a key must not panic
+	if s := common.NewIncreaseBalance().Interpret(); s != "IncreaseBalance nil NumEC 0" {
+		t.Errorf("unexpected IncreaseBalance summary: %s", s)
+	}
+}
+
+func TestECBlockForEachEntry(t *testing.T) {
+	ecb := common.NewECBlock()
+
+	// an empty block never calls fn
+	err := ecb.ForEachEntry(func(common.ECBlockEntry) error {
+		t.Errorf("fn called on an empty ECBlock")
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	ib1 := common.NewIncreaseBalance()
+	ib1.NumEC = 1
+	ib2 := common.NewIncreaseBalance()
+	ib2.NumEC = 2
+	ecb.AddEntry(common.NewServerIndexNumber(), ib1, nil, common.NewMinuteNumber(), ib2)
+
+	count := 0
+	if err := ecb.ForEachEntry(func(common.ECBlockEntry) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+	if count != 4 {
+		t.Errorf("visited %v entries, expected 4", count)
+	}
+
+	// stop at the first error
+	stop := errors.New("stop")
+	count = 0
+	if err := ecb.ForEachEntry(func(common.ECBlockEntry) error {
+		count++
+		if count == 2 {
+			return stop
+		}
+		return nil
+	}); err != stop {
+		t.Errorf("expected the error from fn, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("visited %v entries after the error, expected 2", count)
+	}
+
+	var total uint64
+	if err := ecb.ForEachIncreaseBalance(func(b *common.IncreaseBalance) error {
+		total += b.NumEC
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+	if total != 3 {
+		t.Errorf("IncreaseBalance total is %v, expected 3", total)
+	}
+}
+
+func TestECBlockEntryHash(t *testing.T) {
+	cc := common.NewCommitChain()
+	cc.Credits = 11
+	ce := common.NewCommitEntry()
+	ce.Credits = 1
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = new([32]byte)
+	ib.NumEC = 1
+	mn := common.NewMinuteNumber()
+	mn.Number = 2
+	si := common.NewServerIndexNumber()
+	si.Number = 2
+
+	seen := make(map[string]string)
+	for _, e := range []common.ECBlockEntry{cc, ce, ib, mn, si} {
+		h1, err := common.ECBlockEntryHash(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := common.ECBlockEntryHash(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !h1.IsSameAs(h2) {
+			t.Errorf("ECID %v: hash changed between calls", e.ECID())
+		}
+		if !e.Hash().IsSameAs(h1) {
+			t.Errorf("ECID %v: Hash is not the ECBlockEntryHash", e.ECID())
+		}
+		if g, ok := e.(interface {
+			GetHash() *common.Hash
+		}); ok && !g.GetHash().IsSameAs(h1) {
+			t.Errorf("ECID %v: GetHash is not the ECBlockEntryHash", e.ECID())
+		}
+		if other, ok := seen[h1.String()]; ok {
+			t.Errorf("ECID %v has the same hash as %s", e.ECID(), other)
+		}
+		seen[h1.String()] = e.Interpret()
+	}
+
+	// a copy of an entry has the same hash, a changed entry does not
+	ce2 := common.NewCommitEntry()
+	ce2.Credits = 1
+	h1, _ := common.ECBlockEntryHash(ce)
+	h2, _ := common.ECBlockEntryHash(ce2)
+	if !h1.IsSameAs(h2) {
+		t.Errorf("identical CommitEntries have different hashes")
+	}
+	ce2.Credits = 2
+	h2, _ = common.ECBlockEntryHash(ce2)
+	if h1.IsSameAs(h2) {
+		t.Errorf("different CommitEntries have the same hash")
+	}
+}
+
+func TestECBlockAddUniqueEntry(t *testing.T) {
+	ecb := common.NewECBlock()
+	ce := common.NewCommitEntry()
+	ce.Credits = 1
+	ce2 := common.NewCommitEntry()
+	ce2.Credits = 1
+
+	if added, err := ecb.AddUniqueEntry(ce); err != nil || !added {
+		t.Fatalf("first CommitEntry not added: %v %v", added, err)
+	}
+	// an identical entry, as from a retried submission, is not added again
+	if added, err := ecb.AddUniqueEntry(ce2); err != nil || added {
+		t.Errorf("duplicate CommitEntry added: %v %v", added, err)
+	}
+	if len(ecb.Body.Entries) != 1 {
+		t.Errorf("expected 1 entry, got %v", len(ecb.Body.Entries))
+	}
+
+	// an entry added with AddEntry is found too
+	cc := common.NewCommitChain()
+	cc.Credits = 11
+	ecb.AddEntry(cc)
+	cc2 := common.NewCommitChain()
+	cc2.Credits = 11
+	if added, err := ecb.AddUniqueEntry(cc2); err != nil || added {
+		t.Errorf("duplicate CommitChain added: %v %v", added, err)
+	}
+	if len(ecb.Body.Entries) != 2 {
+		t.Errorf("expected 2 entries, got %v", len(ecb.Body.Entries))
+	}
+
+	// markers are always added
+	mn := common.NewMinuteNumber()
+	mn.Number = 1
+	for i := 0; i < 2; i++ {
+		if added, err := ecb.AddUniqueEntry(mn); err != nil || !added {
+			t.Errorf("MinuteNumber not added: %v %v", added, err)
+		}
+	}
+	if len(ecb.Body.Entries) != 4 {
+		t.Errorf("expected 4 entries, got %v", len(ecb.Body.Entries))
+	}
+
+	// after the body is replaced, only its own entries count
+	ecb.Body = common.NewECBlockBody()
+	ecb.AddEntry(mn, mn, mn, mn)
+	if added, err := ecb.AddUniqueEntry(ce2); err != nil || !added {
+		t.Errorf("CommitEntry not added to the replaced body: %v %v", added, err)
+	}
+}
+
+func TestECBlockMarshalSkipsNilEntries(t *testing.T) {
+	ecb := common.NewECBlock()
+	m := common.NewMinuteNumber()
+	m.Number = 1
+	ce := common.NewCommitEntry()
+	ce.Credits = 1
+	ecb.Body.Entries = append(ecb.Body.Entries, m, nil, ce)
+
+	p, err := ecb.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ecb.Header.ObjectCount != 2 {
+		t.Errorf("ObjectCount %v counts the nil entry", ecb.Header.ObjectCount)
+	}
+
+	ecb2 := common.NewECBlock()
+	if err := ecb2.UnmarshalBinary(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(ecb2.Body.Entries) != 2 {
+		t.Errorf("expected 2 entries, got %v", len(ecb2.Body.Entries))
+	}
+}
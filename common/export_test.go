@@ -0,0 +1,18 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package common
+
+// CreditSchedules returns a copy of the credit schedules.
+func CreditSchedules() []CreditSchedule {
+	return append([]CreditSchedule(nil), creditSchedules...)
+}
+
+// SetCreditSchedules replaces the credit schedules for a test and returns a
+// function that restores them.
+func SetCreditSchedules(s []CreditSchedule) (restore func()) {
+	saved := creditSchedules
+	creditSchedules = s
+	return func() { creditSchedules = saved }
+}
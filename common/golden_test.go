@@ -0,0 +1,179 @@
+package common_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/FactomProject/FactomCode/common"
+)
+
+// Run "go test -update" to rewrite the golden files after an intentional
+// change to the wire format. Such a change must also bump the Version of the
+// affected structure.
+var updateGolden = flag.Bool("update", false, "rewrite the golden serialization fixtures in testdata")
+
+type goldenFixture struct {
+	name string
+	obj  interface {
+		MarshalBinary() ([]byte, error)
+	}
+}
+
+// goldenFixtures returns one representative instance of each serialized type,
+// built only from fixed inputs so that its marshaled bytes never change.
+func goldenFixtures() []goldenFixture {
+	cc := common.NewCommitChain()
+	cc.MilliTime = &[6]byte{1, 2, 3, 4, 5, 6}
+	cc.ChainIDHash.SetBytes(byteof(0xaa))
+	cc.Weld.SetBytes(byteof(0xbb))
+	cc.EntryHash.SetBytes(byteof(0xcc))
+	cc.Credits = 11
+	copy(cc.ECPubKey[:], byteof(0xdd))
+	copy(cc.Sig[:], append(byteof(0xee), byteof(0xff)...))
+
+	ce := common.NewCommitEntry()
+	ce.MilliTime = &[6]byte{1, 2, 3, 4, 5, 6}
+	ce.EntryHash.SetBytes(byteof(0xcc))
+	ce.Credits = 1
+	copy(ce.ECPubKey[:], byteof(0xdd))
+	copy(ce.Sig[:], append(byteof(0xee), byteof(0xff)...))
+
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = new([32]byte)
+	copy(ib.ECPubKey[:], byteof(0xdd))
+	ib.TXID.SetBytes(byteof(0x99))
+	ib.Index = 2
+	ib.NumEC = 100
+
+	mn := common.NewMinuteNumber()
+	mn.Number = 7
+
+	si := common.NewServerIndexNumber()
+	si.Number = 3
+
+	ecb := common.NewECBlock()
+	ecb.Header.ECChainID.SetBytes(byteof(0x11))
+	ecb.Header.PrevHeaderHash.SetBytes(byteof(0x33))
+	ecb.Header.PrevLedgerKeyMR.SetBytes(byteof(0x44))
+	ecb.Header.EBHeight = 10
+	ecb.Header.HeaderExpansionArea = byteof(0x55)
+	ecb.AddEntry(si, cc, ce, ib, mn)
+
+	entry := common.NewEntry()
+	entry.ChainID.SetBytes(byteof(0x11))
+	entry.ExtIDs = [][]byte{[]byte("golden"), []byte("fixture")}
+	entry.Content = []byte("Factom wire format")
+
+	eb := common.NewEBlock()
+	eb.Header.ChainID.SetBytes(byteof(0x11))
+	eb.Header.BodyMR.SetBytes(byteof(0x22))
+	eb.Header.PrevKeyMR.SetBytes(byteof(0x33))
+	eb.Header.PrevLedgerKeyMR.SetBytes(byteof(0x44))
+	eb.Header.EBSequence = 5
+	eb.Header.EBHeight = 6
+	eb.Header.EntryCount = 2
+	ha := common.NewHash()
+	ha.SetBytes(byteof(0xaa))
+	hb := common.NewHash()
+	hb.SetBytes(byteof(0xbb))
+	eb.Body.EBEntries = append(eb.Body.EBEntries, ha)
+	eb.AddEndOfMinuteMarker(0x01)
+	eb.Body.EBEntries = append(eb.Body.EBEntries, hb)
+
+	ecc := common.NewECChain()
+	ecc.Name = [][]byte{[]byte("entry credit"), []byte("chain")}
+
+	dbi := new(common.DirBlockInfo)
+	dbi.DBHash = common.NewHash()
+	dbi.DBHash.SetBytes(byteof(0x11))
+	dbi.DBHeight = 12
+	dbi.Timestamp = 1440000000
+	dbi.BTCTxHash = common.NewHash()
+	dbi.BTCTxHash.SetBytes(byteof(0x22))
+	dbi.BTCTxOffset = 3
+	dbi.BTCBlockHeight = 370000
+	dbi.BTCBlockHash = common.NewHash()
+	dbi.BTCBlockHash.SetBytes(byteof(0x33))
+	dbi.DBMerkleRoot = common.NewHash()
+	dbi.DBMerkleRoot.SetBytes(byteof(0x44))
+	dbi.BTCConfirmed = true
+
+	pub := new([32]byte)
+	copy(pub[:], byteof(0x77))
+	sig := new([64]byte)
+	copy(sig[:], append(byteof(0x88), byteof(0x99)...))
+	sigChainID := common.NewHash()
+	sigChainID.SetBytes(byteof(0x66))
+	dbsig := common.NewDBSignatureEntry(sigChainID, common.Signature{Pub: common.PublicKey{Key: pub}, Sig: sig})
+
+	ab := new(common.AdminBlock)
+	ab.Header = new(common.ABlockHeader)
+	ab.Header.AdminChainID = common.NewHash()
+	ab.Header.AdminChainID.SetBytes(common.ADMIN_CHAINID)
+	ab.Header.PrevLedgerKeyMR = common.NewHash()
+	ab.Header.PrevLedgerKeyMR.SetBytes(byteof(0x55))
+	ab.Header.DBHeight = 12
+	ab.AddABEntry(dbsig)
+	ab.AddEndOfMinuteMarker(3)
+	eom := ab.ABEntries[1]
+	ab.Header.MessageCount = uint32(len(ab.ABEntries))
+	ab.Header.BodySize = uint32(dbsig.MarshalledSize() + eom.MarshalledSize())
+
+	return []goldenFixture{
+		{"commitchain", cc},
+		{"commitentry", ce},
+		{"increasebalance", ib},
+		{"minutenumber", mn},
+		{"serverindexnumber", si},
+		{"ecblock", ecb},
+		{"entry", entry},
+		{"eblock", eb},
+		{"dblockheader", createTestDirectoryBlockHeader()},
+		{"dblock", createTestDirectoryBlock()},
+		{"ecchain", ecc},
+		{"dirblockinfo", dbi},
+		{"dbsignatureentry", dbsig},
+		{"endofminuteentry", eom},
+		{"ablockheader", ab.Header},
+		{"adminblock", ab},
+	}
+}
+
+func TestGoldenSerialization(t *testing.T) {
+	fmt.Println("\n---\nTestGoldenSerialization\n---\n")
+
+	for _, f := range goldenFixtures() {
+		p, err := f.obj.MarshalBinary()
+		if err != nil {
+			t.Errorf("%s: %v", f.name, err)
+			continue
+		}
+
+		file := filepath.Join("testdata", f.name+".hex")
+		if *updateGolden {
+			if err := ioutil.WriteFile(file, []byte(hex.EncodeToString(p)+"\n"), 0644); err != nil {
+				t.Errorf("%s: %v", f.name, err)
+			}
+			continue
+		}
+
+		golden, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Errorf("%s: %v", f.name, err)
+			continue
+		}
+		expected, err := hex.DecodeString(string(bytes.TrimSpace(golden)))
+		if err != nil {
+			t.Errorf("%s: %v", f.name, err)
+			continue
+		}
+		if !bytes.Equal(p, expected) {
+			t.Errorf("%s: serialization does not match %s\n got: %x\nwant: %x", f.name, file, p, expected)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package common
 
 import (
 	"bytes"
+	"fmt"
 )
 
 //var IncreaseBalanceSize int = 32 + 4 + 32
@@ -34,11 +35,11 @@ func NewIncreaseBalance() *IncreaseBalance {
 }
 
 func (e *IncreaseBalance) Hash() *Hash {
-	bin, err := e.MarshalBinary()
+	h, err := ECBlockEntryHash(e)
 	if err != nil {
 		panic(err)
 	}
-	return Sha(bin)
+	return h
 }
 
 func (b *IncreaseBalance) ECID() byte {
@@ -46,11 +47,11 @@ func (b *IncreaseBalance) ECID() byte {
 }
 
 func (b *IncreaseBalance) IsInterpretable() bool {
-	return false
+	return true
 }
 
 func (b *IncreaseBalance) Interpret() string {
-	return ""
+	return fmt.Sprintf("IncreaseBalance %s NumEC %v", shortKey(b.ECPubKey), b.NumEC)
 }
 
 func (b *IncreaseBalance) MarshalBinary() ([]byte, error) {
@@ -5,6 +5,7 @@
 package common
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -35,7 +36,19 @@ func hashMerkleBranches(left *Hash, right *Hash) *Hash {
 	return newSha
 }
 
+// BuildMerkleTreeStore builds a merkle tree from hashes and returns it as a
+// linear array: the leaves padded with nil up to the next power of two, then
+// each level of parents, ending with the merkle root. Where a level has more
+// than one node, a node without a right sibling is hashed with itself, so an
+// odd node is never promoted unhashed. This rule decides the root and must not
+// change. A single hash is its own root. An empty list of hashes returns an
+// empty array, which has no root; callers that need one use Sha(nil) as the
+// only leaf, as DirectoryBlock.BuildBodyMR does.
 func BuildMerkleTreeStore(hashes []*Hash) (merkles []*Hash) {
+	if len(hashes) == 0 {
+		return []*Hash{}
+	}
+
 	// Calculate how many entries are required to hold the binary merkle
 	// tree as a linear array and create an array of that size.
 	nextPoT := nextPowerOfTwo(len(hashes))
@@ -74,3 +87,57 @@ func BuildMerkleTreeStore(hashes []*Hash) (merkles []*Hash) {
 	}
 	return merkles
 }
+
+// BuildMerkleProof returns the sibling hashes, from the leaf level up, needed
+// to recompute the merkle root of hashes from the hash at index. A node with
+// no right sibling is hashed with itself, so it is its own sibling.
+func BuildMerkleProof(hashes []*Hash, index int) ([]*Hash, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, fmt.Errorf("Merkle proof index %v out of range, %v hashes", index, len(hashes))
+	}
+
+	merkles := BuildMerkleTreeStore(hashes)
+	proof := make([]*Hash, 0)
+	levelStart := 0
+	for width := nextPowerOfTwo(len(hashes)); width > 1; width /= 2 {
+		sibling := merkles[levelStart+(index^1)]
+		if sibling == nil {
+			sibling = merkles[levelStart+index]
+		}
+		proof = append(proof, sibling)
+		levelStart += width
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof checks that hash is the leaf at index of the merkle tree
+// of count leaves with the given root, using a proof from BuildMerkleProof.
+// The count must come from a trusted source, such as the header of the block,
+// since the last leaf of an odd level is hashed with itself and its proof
+// would also match the root at the index after it.
+func VerifyMerkleProof(hash *Hash, index int, count int, proof []*Hash, root *Hash) bool {
+	if hash == nil || index < 0 || index >= count {
+		return false
+	}
+
+	h := hash
+	width := count // nodes at the current level
+	for _, sibling := range proof {
+		if width == 1 || sibling == nil {
+			return false
+		}
+		if index%2 == 0 {
+			// the last node of an odd level is its own sibling
+			if index == width-1 && !sibling.IsSameAs(h) {
+				return false
+			}
+			h = hashMerkleBranches(h, sibling)
+		} else {
+			h = hashMerkleBranches(sibling, h)
+		}
+		index /= 2
+		width = (width + 1) / 2
+	}
+	return width == 1 && h.IsSameAs(root)
+}
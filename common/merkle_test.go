@@ -0,0 +1,39 @@
+package common_test
+
+import (
+	"testing"
+
+	. "github.com/FactomProject/FactomCode/common"
+)
+
+func TestBuildMerkleTreeStore(t *testing.T) {
+	// roots for leaves 0x01..., 0x02..., pinned so every node computes the
+	// same roots for odd numbers of leaves
+	roots := map[int]string{
+		1: "0101010101010101010101010101010101010101010101010101010101010101",
+		2: "f818afd37a6dc3bc92fb44731011277006db4efa6e9023cd7468c02335d22a4d",
+		3: "831e18b32b5392c031f24c715086821d7532fcb6cac0bb815a1a647990cff261",
+		5: "f632ed650b4b2c467228719df716e74232b7293f997e481489bd172e73473a77",
+	}
+
+	for n, root := range roots {
+		hashes := make([]*Hash, n)
+		for i := range hashes {
+			hashes[i] = NewHash()
+			hashes[i].SetBytes(byteof(byte(i + 1)))
+		}
+
+		merkles := BuildMerkleTreeStore(hashes)
+		if len(merkles) == 0 {
+			t.Errorf("%v leaves: empty tree", n)
+			continue
+		}
+		if s := merkles[len(merkles)-1].String(); s != root {
+			t.Errorf("%v leaves: root %s, expected %s", n, s, root)
+		}
+	}
+
+	if merkles := BuildMerkleTreeStore(nil); len(merkles) != 0 {
+		t.Errorf("empty input built a tree of %v nodes", len(merkles))
+	}
+}
@@ -23,11 +23,11 @@ var _ ShortInterpretable = (*MinuteNumber)(nil)
 var _ ECBlockEntry = (*MinuteNumber)(nil)
 
 func (e *MinuteNumber) Hash() *Hash {
-	bin, err := e.MarshalBinary()
+	h, err := ECBlockEntryHash(e)
 	if err != nil {
 		panic(err)
 	}
-	return Sha(bin)
+	return h
 }
 
 func (b *MinuteNumber) IsInterpretable() bool {
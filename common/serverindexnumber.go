@@ -23,11 +23,11 @@ var _ ShortInterpretable = (*ServerIndexNumber)(nil)
 var _ ECBlockEntry = (*ServerIndexNumber)(nil)
 
 func (e *ServerIndexNumber) Hash() *Hash {
-	bin, err := e.MarshalBinary()
+	h, err := ECBlockEntryHash(e)
 	if err != nil {
 		panic(err)
 	}
-	return Sha(bin)
+	return h
 }
 
 func (b *ServerIndexNumber) IsInterpretable() bool {
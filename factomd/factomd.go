@@ -106,7 +106,11 @@ func factomdMain() error {
 	hash, _ := db.FetchDBHashByHeight(0)
 	if hash != nil {
 		for true {
-			latestDirBlockHash, _, _ := db.FetchBlockHeightCache()
+			latestDirBlockHash, _, err := db.FetchBlockHeightCache()
+			if err != nil {
+				ftmdLog.Errorf("Error reading the directory block height from db: %v", err)
+				return err
+			}
 			if latestDirBlockHash == nil {
 				ftmdLog.Info("Waiting for the processor to be initialized...")
 				time.Sleep(2 * time.Second)
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -165,6 +166,17 @@ var levelPrefix = map[Level]string{
 	Debug:     "DEBUG",
 }
 
+// String returns the name of the level as it is written in the config file.
+func (level Level) String() string {
+	if level == None {
+		return "none"
+	}
+	if p, ok := levelPrefix[level]; ok {
+		return strings.ToLower(p)
+	}
+	return fmt.Sprintf("level(%d)", int8(level))
+}
+
 func levelFromString(levelName string) (level Level) {
 	switch levelName {
 	case "debug":
@@ -19,3 +19,12 @@ func TestNew(t *testing.T) {
 
 	fmt.Print(&buf)
 }
+
+func TestLevelString(t *testing.T) {
+	for _, name := range []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency", "none"} {
+		logger := New(new(bytes.Buffer), name, "testing")
+		if logger.Level().String() != name {
+			t.Errorf("level %q is shown as %q", name, logger.Level().String())
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/FactomProject/FactomCode/common"
+)
+
+// chargedEntries holds the ECBlockEntryHash of the commits and balance
+// increases whose credits were taken from or added to eCreditMap, with the
+// time until which each could still be replayed. A replayed commit or factoid
+// transaction is rejected against it before its credits are counted again, so
+// that the balances in memory match the ones rebuilt from the ECBlocks.
+type chargedEntries map[string]time.Time
+
+var chargedECEntries = make(chargedEntries)
+
+// check returns an error if any of the entries has already been charged.
+func (c chargedEntries) check(entries ...common.ECBlockEntry) error {
+	for _, e := range entries {
+		if _, exist := c[e.Hash().String()]; exist {
+			return fmt.Errorf("Duplicate %s, its credits have already been counted", e.Interpret())
+		}
+	}
+	return nil
+}
+
+// add records the entries as charged at now. A commit is kept until its
+// MilliTime falls out of the COMMIT_TIME_WINDOW, after which InTime rejects a
+// replay of it. A balance increase is kept for the same window from now.
+func (c chargedEntries) add(now time.Time, entries ...common.ECBlockEntry) {
+	window := common.COMMIT_TIME_WINDOW * time.Hour
+	for _, e := range entries {
+		expires := now.Add(window)
+		switch v := e.(type) {
+		case *common.CommitEntry:
+			expires = time.Unix(0, v.GetMilliTime()*int64(time.Millisecond)).Add(window)
+		case *common.CommitChain:
+			expires = time.Unix(0, v.GetMilliTime()*int64(time.Millisecond)).Add(window)
+		}
+		c[e.Hash().String()] = expires
+	}
+}
+
+// prune removes the entries that can no longer be replayed at now.
+func (c chargedEntries) prune(now time.Time) {
+	for k, expires := range c {
+		if now.After(expires) {
+			delete(c, k)
+		}
+	}
+}
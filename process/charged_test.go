@@ -0,0 +1,79 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/FactomCode/common"
+)
+
+// milliTime returns t as the 6 byte MilliTime of a commit.
+func milliTime(t time.Time) *[6]byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(t.UnixNano()/int64(time.Millisecond)))
+	m := new([6]byte)
+	copy(m[:], b[2:])
+	return m
+}
+
+func TestChargedEntries(t *testing.T) {
+	c := make(chargedEntries)
+	now := time.Now()
+
+	ce := common.NewCommitEntry()
+	ce.Credits = 1
+	ce.MilliTime = milliTime(now)
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = new([32]byte)
+	ib.NumEC = 10
+
+	if err := c.check(ce, ib); err != nil {
+		t.Errorf("new entries rejected: %v", err)
+	}
+	c.add(now, ce, ib)
+
+	// an identical commit, as from a replayed message, is rejected
+	ce2 := common.NewCommitEntry()
+	ce2.Credits = 1
+	ce2.MilliTime = milliTime(now)
+	if err := c.check(ce2); err == nil {
+		t.Errorf("duplicate CommitEntry accepted")
+	}
+	if err := c.check(ce, ib); err == nil {
+		t.Errorf("duplicate entries accepted")
+	}
+
+	// a different commit is not
+	ce2.Credits = 2
+	if err := c.check(ce2); err != nil {
+		t.Errorf("different CommitEntry rejected: %v", err)
+	}
+}
+
+func TestChargedEntriesReplayAfterBlock(t *testing.T) {
+	c := make(chargedEntries)
+	now := time.Now()
+
+	// a commit charged in one block and revealed
+	ce := common.NewCommitEntry()
+	ce.Credits = 1
+	ce.MilliTime = milliTime(now)
+	c.add(now, ce)
+
+	// is still rejected when replayed after the next ECBlock opens
+	c.prune(now.Add(10 * time.Minute))
+	if err := c.check(ce); err == nil {
+		t.Errorf("CommitEntry replayed after a new ECBlock accepted")
+	}
+
+	// and is only dropped once InTime would reject it
+	c.prune(now.Add(common.COMMIT_TIME_WINDOW*time.Hour + time.Minute))
+	if len(c) != 0 {
+		t.Errorf("expected the CommitEntry to be pruned, %v entries left", len(c))
+	}
+}
@@ -90,7 +90,9 @@ func initECChain() {
 
 	// Calculate the EC balance for each account
 	for _, v := range ecBlocks {
-		initializeECreditMap(&v)
+		if err := initializeECreditMap(&v); err != nil {
+			panic(err.Error())
+		}
 	}
 
 	//Create an empty block and append to the chain
@@ -236,29 +238,37 @@ func initEChains() {
 
 }
 
-// Re-calculate Entry Credit Balance Map with a new Entry Credit Block
-func initializeECreditMap(block *common.ECBlock) {
-	for _, entry := range block.Body.Entries {
+// Re-calculate Entry Credit Balance Map with a new Entry Credit Block. An error
+// is returned for a block that would overflow a balance.
+func initializeECreditMap(block *common.ECBlock) error {
+	return block.ForEachEntry(func(entry common.ECBlockEntry) error {
 		// Only process: ECIDChainCommit, ECIDEntryCommit, ECIDBalanceIncrease
 		switch entry.ECID() {
 		case common.ECIDChainCommit:
 			e := entry.(*common.CommitChain)
-			eCreditMap[string(e.ECPubKey[:])] -= int32(e.Credits)
+			if err := common.SubECCredits(eCreditMap, e.ECPubKey, uint64(e.Credits)); err != nil {
+				return fmt.Errorf("CommitChain in ECBlock %v: %v", block.Header.EBHeight, err)
+			}
 			common.FactoidState.UpdateECBalance(fct.NewAddress(e.ECPubKey[:]), int64(e.Credits))
 		case common.ECIDEntryCommit:
 			e := entry.(*common.CommitEntry)
-			eCreditMap[string(e.ECPubKey[:])] -= int32(e.Credits)
+			if err := common.SubECCredits(eCreditMap, e.ECPubKey, uint64(e.Credits)); err != nil {
+				return fmt.Errorf("CommitEntry in ECBlock %v: %v", block.Header.EBHeight, err)
+			}
 			common.FactoidState.UpdateECBalance(fct.NewAddress(e.ECPubKey[:]), int64(e.Credits))
 		case common.ECIDBalanceIncrease:
 			e := entry.(*common.IncreaseBalance)
-			eCreditMap[string(e.ECPubKey[:])] += int32(e.NumEC)
+			if err := common.AddECCredits(eCreditMap, e.ECPubKey, e.NumEC); err != nil {
+				return fmt.Errorf("IncreaseBalance in ECBlock %v: %v", block.Header.EBHeight, err)
+			}
 			// Don't add the Increases to Factoid state, the Factoid processing will do that.
 		case common.ECIDServerIndexNumber:
 		case common.ECIDMinuteNumber:
 		default:
-			panic("Unknow entry type:" + string(entry.ECID()) + " for ECBlock:" + strconv.FormatUint(uint64(block.Header.EBHeight), 10))
+			return fmt.Errorf("Unknown entry type %v for ECBlock %v", entry.ECID(), block.Header.EBHeight)
 		}
-	}
+		return nil
+	})
 }
 
 // Initialize server private key and server public key for milestone 1
@@ -13,7 +13,6 @@
 package process
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"sort"
@@ -81,6 +80,7 @@ var (
 
 var (
 	directoryBlockInSeconds int
+	slowMsgThreshold        time.Duration
 	dataStorePath           string
 	ldbpath                 string
 	nodeMode                string
@@ -97,6 +97,7 @@ func LoadConfigurations(cfg *util.FactomdConfig) {
 	dataStorePath = cfg.App.DataStorePath
 	ldbpath = cfg.App.LdbPath
 	directoryBlockInSeconds = cfg.App.DirectoryBlockInSeconds
+	slowMsgThreshold = time.Duration(cfg.App.SlowMsgInMilliseconds) * time.Millisecond
 	nodeMode = cfg.App.NodeMode
 	serverPrivKeyHex = cfg.App.ServerPrivKey
 
@@ -106,6 +107,48 @@ func LoadConfigurations(cfg *util.FactomdConfig) {
 	FactomdPass = cfg.Btc.RpcPass
 }
 
+// EffectiveConfig holds the settings the processor is running with. Only
+// settings that are safe to show are copied into it, so that a new secret in
+// the config file is never shown by mistake.
+type EffectiveConfig struct {
+	NodeMode                string
+	DirectoryBlockInSeconds int
+	SlowMsgThreshold        time.Duration
+	ExchangeRate            uint64
+	LdbPath                 string
+	DataStorePath           string
+	LogLevel                string
+	ControlPanelPort        string
+}
+
+// GetEffectiveConfig returns the settings the processor is running with. They
+// are read once by LoadConfigurations, except the exchange rate, which is read
+// again for every factoid block, so they can differ from the config file. The
+// log level is the one procLog was created with.
+func GetEffectiveConfig() *EffectiveConfig {
+	return &EffectiveConfig{
+		NodeMode:                nodeMode,
+		DirectoryBlockInSeconds: directoryBlockInSeconds,
+		SlowMsgThreshold:        slowMsgThreshold,
+		ExchangeRate:            FactoshisPerCredit,
+		LdbPath:                 ldbpath,
+		DataStorePath:           dataStorePath,
+		LogLevel:                procLog.Level().String(),
+		ControlPanelPort:        cp.CP.GetPort(),
+	}
+}
+
+// updateConfigStatus shows the effective config on the control panel, so that
+// it can be checked while the node runs.
+func updateConfigStatus() {
+	cp.CP.AddUpdate(
+		"Config",                  // tag
+		"status",                  // Category
+		"Effective Configuration", // Title
+		fmt.Sprintf("%+v", *GetEffectiveConfig()),
+		0)
+}
+
 // Initialize the processor
 func initProcessor() {
 
@@ -193,6 +236,9 @@ func Start_Processor(
 
 	initProcessor()
 
+	procLog.Infof("Processor configuration: %+v", *GetEffectiveConfig())
+	updateConfigStatus()
+
 	// Initialize timer for the open dblock before processing messages
 	if nodeMode == common.SERVER_NODE {
 		timer := &BlockTimer{
@@ -216,18 +262,19 @@ func Start_Processor(
 			case msg, ok := <-inMsgQ:
 				if ok {
 
-					if err := serveMsgRequest(msg); err != nil {
-						procLog.Error(err)
+					if err := timedServeMsgRequest(msg); err != nil {
+						rejectedMsgLog.reject(msg.Command(), err, time.Now())
 					}
 				}
 			case ctlMsg, ok := <-inCtlMsgQueue:
 				if ok {
-					if err := serveMsgRequest(ctlMsg); err != nil {
+					if err := timedServeMsgRequest(ctlMsg); err != nil {
 						procLog.Error(err)
 					}
 				}
 			default:
 				time.Sleep(time.Duration(10) * time.Millisecond)
+				rejectedMsgLog.flush(time.Now())
 				if SafeStop {
 					procLog.Info("Closing database")
 					db.Close()
@@ -253,6 +300,22 @@ func serveCtlMsgRequest(msg wire.FtmInternalMsg) error {
 
 }
 
+// Serve incoming msg and log a warning if it takes longer than the
+// configured threshold to process
+func timedServeMsgRequest(msg wire.FtmInternalMsg) error {
+	if slowMsgThreshold <= 0 {
+		return serveMsgRequest(msg)
+	}
+
+	start := time.Now()
+	err := serveMsgRequest(msg)
+	if elapsed := time.Since(start); elapsed > slowMsgThreshold {
+		procLog.Warningf("Slow message: %s took %v to process (threshold %v)",
+			msg.Command(), elapsed, slowMsgThreshold)
+	}
+	return err
+}
+
 // Serve incoming msg from inMsgQueue
 func serveMsgRequest(msg wire.FtmInternalMsg) error {
 
@@ -412,6 +475,16 @@ func serveMsgRequest(msg wire.FtmInternalMsg) error {
 		// Handle the server case
 		if nodeMode == common.SERVER_NODE {
 			t := msgFactoidTX.Transaction
+			// check that the entry credits bought have not been added already,
+			// before the transaction is added to the factoid state
+			ibs := increaseBalances(t)
+			if err := chargedECEntries.check(ibs...); err != nil {
+				return err
+			}
+			// or that they would overflow a balance
+			if _, err := increasedECBalances(ibs); err != nil {
+				return err
+			}
 			txnum := len(common.FactoidState.GetCurrentBlock().GetTransactions())
 			if common.FactoidState.AddTransaction(txnum, t) == nil {
 				if err := processBuyEntryCredit(msgFactoidTX); err != nil {
@@ -578,12 +651,6 @@ func processRevealEntry(msg *wire.MsgRevealEntry) error {
 				msg.Entry.ChainID.String())
 		}
 
-		// add new chain to chainIDMap
-		newChain := common.NewEChain()
-		newChain.ChainID = e.ChainID
-		newChain.FirstEntry = e
-		chainIDMap[e.ChainID.String()] = newChain
-
 		// Calculate the entry credits required for the entry
 		cred, err := util.EntryCost(bin)
 		if err != nil {
@@ -602,17 +669,16 @@ func processRevealEntry(msg *wire.MsgRevealEntry) error {
 			return fmt.Errorf("Invalid ChainID for entry: %s", e.Hash().String())
 		}
 
-		//validate chainid hash in the commitChain
-		chainIDHash := common.DoubleSha(e.ChainID.Bytes())
-		if !bytes.Equal(c.ChainIDHash.Bytes()[:], chainIDHash[:]) {
-			return fmt.Errorf("RevealChain's chainid hash does not match with CommitChain: %s", e.Hash().String())
+		//validate chainid hash and Weld in the commitChain
+		if !c.IsValidWeld(e.ChainID) {
+			return fmt.Errorf("RevealChain's chainid hash or weld does not match with CommitChain: %s", e.Hash().String())
 		}
 
-		//validate Weld in the commitChain
-		weld := common.DoubleSha(append(c.EntryHash.Bytes(), e.ChainID.Bytes()...))
-		if !bytes.Equal(c.Weld.Bytes()[:], weld[:]) {
-			return fmt.Errorf("RevealChain's weld does not match with CommitChain: %s", e.Hash().String())
-		}
+		// add new chain to chainIDMap
+		newChain := common.NewEChain()
+		newChain.ChainID = e.ChainID
+		newChain.FirstEntry = e
+		chainIDMap[e.ChainID.String()] = newChain
 
 		// Add the msg to the Mem pool
 		fMemPool.addMsg(msg, h)
@@ -656,10 +722,22 @@ func processCommitEntry(msg *wire.MsgCommitEntry) error {
 		return fmt.Errorf("Cannot commit entry, entry has already been commited")
 	}
 
+	// check that the same commit has not been paid for already, such as a
+	// replay after its entry was revealed
+	if err := chargedECEntries.check(c); err != nil {
+		return err
+	}
+
 	if c.Credits > common.MAX_ENTRY_CREDITS {
 		return fmt.Errorf("Commit entry exceeds the max entry credit limit:" + c.EntryHash.String())
 	}
 
+	// New commits must meet the newest credit schedule, so that the blocks
+	// built from them stay valid once it takes effect
+	if c.Credits < common.LatestCreditSchedule().MinEntryCredits {
+		return fmt.Errorf("Commit entry is below the min entry credit limit: %s", c.EntryHash)
+	}
+
 	// Check the entry credit balance
 	if eCreditMap[string(c.ECPubKey[:])] < int32(c.Credits) {
 		return fmt.Errorf("Not enough credits for CommitEntry")
@@ -672,7 +750,10 @@ func processCommitEntry(msg *wire.MsgCommitEntry) error {
 	if nodeMode == common.SERVER_NODE {
 
 		// deduct the entry credits from the eCreditMap
-		eCreditMap[string(c.ECPubKey[:])] -= int32(c.Credits)
+		if err := common.SubECCredits(eCreditMap, c.ECPubKey, uint64(c.Credits)); err != nil {
+			return err
+		}
+		chargedECEntries.add(time.Now(), c)
 
 		h, _ := msg.Sha()
 		if plMgr.IsMyPListExceedingLimit() {
@@ -706,10 +787,22 @@ func processCommitChain(msg *wire.MsgCommitChain) error {
 		return fmt.Errorf("Cannot commit chain, first entry for chain already exists")
 	}
 
+	// check that the same commit has not been paid for already, such as a
+	// replay after its chain was revealed
+	if err := chargedECEntries.check(c); err != nil {
+		return err
+	}
+
 	if c.Credits > common.MAX_CHAIN_CREDITS {
 		return fmt.Errorf("Commit chain exceeds the max entry credit limit:" + c.EntryHash.String())
 	}
 
+	// New commits must meet the newest credit schedule, so that the blocks
+	// built from them stay valid once it takes effect
+	if c.Credits < common.LatestCreditSchedule().MinChainCredits {
+		return fmt.Errorf("Commit chain is below the min chain credit limit: %s", c.EntryHash)
+	}
+
 	// Check the entry credit balance
 	if eCreditMap[string(c.ECPubKey[:])] < int32(c.Credits) {
 		return fmt.Errorf("Not enough credits for CommitChain")
@@ -721,7 +814,10 @@ func processCommitChain(msg *wire.MsgCommitChain) error {
 	// Server: add to MyPL
 	if nodeMode == common.SERVER_NODE {
 		// deduct the entry credits from the eCreditMap
-		eCreditMap[string(c.ECPubKey[:])] -= int32(c.Credits)
+		if err := common.SubECCredits(eCreditMap, c.ECPubKey, uint64(c.Credits)); err != nil {
+			return err
+		}
+		chargedECEntries.add(time.Now(), c)
 
 		h, _ := msg.Sha()
 
@@ -745,15 +841,15 @@ func processCommitChain(msg *wire.MsgCommitChain) error {
 // processBuyEntryCredit validates the MsgCommitChain and adds it to processlist
 func processBuyEntryCredit(msg *wire.MsgFactoidTX) error {
 	// Update the credit balance in memory
-	for _, v := range msg.Transaction.GetECOutputs() {
-		pub := new([32]byte)
-		copy(pub[:], v.GetAddress().Bytes())
-
-		cred := int32(v.GetAmount() / uint64(FactoshisPerCredit))
-
-		eCreditMap[string(pub[:])] += cred
-
+	ibs := increaseBalances(msg.Transaction)
+	balances, err := increasedECBalances(ibs)
+	if err != nil {
+		return err
+	}
+	for k, v := range balances {
+		eCreditMap[k] = v
 	}
+	chargedECEntries.add(time.Now(), ibs...)
 
 	h, _ := msg.Sha()
 	if plMgr.IsMyPListExceedingLimit() {
@@ -818,7 +914,15 @@ func buildRevealEntry(msg *wire.MsgRevealEntry) {
 }
 
 func buildIncreaseBalance(msg *wire.MsgFactoidTX) {
-	t := msg.Transaction
+	for _, ib := range increaseBalances(msg.Transaction) {
+		addECBlockEntry(ib)
+	}
+}
+
+// increaseBalances returns the IncreaseBalance entries for the entry credit
+// outputs of a factoid transaction.
+func increaseBalances(t fct.ITransaction) []common.ECBlockEntry {
+	ibs := make([]common.ECBlockEntry, 0)
 	for i, ecout := range t.GetECOutputs() {
 		ib := common.NewIncreaseBalance()
 
@@ -830,21 +934,50 @@ func buildIncreaseBalance(msg *wire.MsgFactoidTX) {
 		th.SetBytes(t.GetHash().Bytes())
 		ib.TXID = th
 
-		cred := int32(ecout.GetAmount() / uint64(FactoshisPerCredit))
-		ib.NumEC = uint64(cred)
+		ib.NumEC = ecout.GetAmount() / uint64(FactoshisPerCredit)
 
 		ib.Index = uint64(i)
 
-		ecchain.NextBlock.AddEntry(ib)
+		ibs = append(ibs, ib)
+	}
+	return ibs
+}
+
+// increasedECBalances returns the entry credit balances of the keys in ibs
+// after the increases, without changing eCreditMap. It returns an error if an
+// increase would overflow a balance.
+func increasedECBalances(ibs []common.ECBlockEntry) (map[string]int32, error) {
+	balances := make(map[string]int32)
+	for _, v := range ibs {
+		ib := v.(*common.IncreaseBalance)
+		key := string(ib.ECPubKey[:])
+		if _, exist := balances[key]; !exist {
+			balances[key] = eCreditMap[key]
+		}
+		if err := common.AddECCredits(balances, ib.ECPubKey, ib.NumEC); err != nil {
+			return nil, err
+		}
 	}
+	return balances, nil
 }
 
 func buildCommitEntry(msg *wire.MsgCommitEntry) {
-	ecchain.NextBlock.AddEntry(msg.CommitEntry)
+	addECBlockEntry(msg.CommitEntry)
 }
 
 func buildCommitChain(msg *wire.MsgCommitChain) {
-	ecchain.NextBlock.AddEntry(msg.CommitChain)
+	addECBlockEntry(msg.CommitChain)
+}
+
+// addECBlockEntry adds the entry to the next ECBlock, skipping an entry that is
+// already in the block, such as a process list item that was delivered twice.
+func addECBlockEntry(entry common.ECBlockEntry) {
+	added, err := ecchain.NextBlock.AddUniqueEntry(entry)
+	if err != nil {
+		procLog.Errorf("Error adding ECID %v to the ECBlock: %v", entry.ECID(), err)
+	} else if !added {
+		procLog.Warningf("Duplicate ECID %v skipped in the ECBlock: %s", entry.ECID(), entry.Interpret())
+	}
 }
 
 func buildRevealChain(msg *wire.MsgRevealEntry) {
@@ -1098,6 +1231,7 @@ func newEntryCreditBlock(chain *common.ECChain) *common.ECBlock {
 		return nil
 	}
 	chain.NextBlock.AddEntry(serverIndex)
+	chargedECEntries.prune(time.Now())
 	chain.BlockMutex.Unlock()
 
 	//Store the block in db
@@ -1173,6 +1307,7 @@ func newFactoidBlock(chain *common.FctChain) block.IFBlock {
 			rate,
 			0)
 	}
+	updateConfigStatus()
 
 	// acquire the last block
 	currentBlock := chain.NextBlock
@@ -1209,21 +1344,19 @@ func newDirectoryBlock(chain *common.DChain) *common.DirectoryBlock {
 
 	// Create the block add a new block for new coming entries
 	chain.BlockMutex.Lock()
-	block.Header.BlockCount = uint32(len(block.DBEntries))
-	// Calculate Merkle Root for FBlock and store it in header
-	if block.Header.BodyMR == nil {
-		block.Header.BodyMR, _ = block.BuildBodyMR()
-		//  Factoid1 block not in the right place...
+	// Build the header and the hashes of the block and seal it
+	if err := block.Seal(); err != nil {
+		panic("Error while sealing the Directory Block: " + err.Error())
 	}
-	block.IsSealed = true
 	chain.AddDBlockToDChain(block)
 	chain.NextDBHeight++
-	chain.NextBlock, _ = common.CreateDBlock(chain, block, 10)
+	var err error
+	chain.NextBlock, err = common.CreateDBlock(chain, block, 10)
+	if err != nil {
+		panic("Error while creating the next Directory Block: " + err.Error())
+	}
 	chain.BlockMutex.Unlock()
 
-	block.DBHash, _ = common.CreateHash(block)
-	block.BuildKeyMerkleRoot()
-
 	//Store the block in db
 	db.ProcessDBlockBatch(block)
 
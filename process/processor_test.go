@@ -0,0 +1,104 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FactomProject/FactomCode/common"
+	cp "github.com/FactomProject/FactomCode/controlpanel"
+	"github.com/FactomProject/FactomCode/util"
+)
+
+func TestGetEffectiveConfig(t *testing.T) {
+	cfg := new(util.FactomdConfig)
+	cfg.App.NodeMode = "SERVER"
+	cfg.App.DirectoryBlockInSeconds = 60
+	cfg.App.SlowMsgInMilliseconds = 500
+	cfg.App.LdbPath = "/tmp/ldb"
+	cfg.App.DataStorePath = "/tmp/export"
+	cfg.App.ServerPrivKey = "secretserverkey"
+	cfg.Btc.RpcUser = "secretuser"
+	cfg.Btc.RpcPass = "secretpass"
+	// a level other than the one procLog was created with
+	cfg.Log.LogLevel = "debug"
+	if procLog.Level().String() == "debug" {
+		cfg.Log.LogLevel = "error"
+	}
+	cfg.Controlpanel.Port = "8091"
+
+	// restore the globals LoadConfigurations sets
+	level, store, ldb := logLevel, dataStorePath, ldbpath
+	seconds, slow := directoryBlockInSeconds, slowMsgThreshold
+	mode, key := nodeMode, serverPrivKeyHex
+	user, pass, port := FactomdUser, FactomdPass, cp.CP.GetPort()
+	defer func() {
+		logLevel, dataStorePath, ldbpath = level, store, ldb
+		directoryBlockInSeconds, slowMsgThreshold = seconds, slow
+		nodeMode, serverPrivKeyHex = mode, key
+		FactomdUser, FactomdPass = user, pass
+		cp.CP.SetPort(port)
+	}()
+
+	LoadConfigurations(cfg)
+
+	// the values the processor holds, not the file, are reported
+	cfg.App.NodeMode = "FULL"
+
+	c := GetEffectiveConfig()
+	if c.NodeMode != "SERVER" || c.DirectoryBlockInSeconds != 60 ||
+		c.SlowMsgThreshold != 500*time.Millisecond || c.LdbPath != "/tmp/ldb" ||
+		c.DataStorePath != "/tmp/export" || c.ControlPanelPort != "8091" {
+		t.Errorf("unexpected effective config %+v", *c)
+	}
+
+	// the level the logger uses, not the one read from the config
+	if c.LogLevel != procLog.Level().String() {
+		t.Errorf("log level %q, the logger uses %q", c.LogLevel, procLog.Level().String())
+	}
+
+	s := fmt.Sprintf("%+v", *c)
+	for _, secret := range []string{"secretserverkey", "secretuser", "secretpass"} {
+		if strings.Contains(s, secret) {
+			t.Errorf("effective config shows %s: %s", secret, s)
+		}
+	}
+}
+
+func TestIncreasedECBalances(t *testing.T) {
+	defer func(m map[string]int32) { eCreditMap = m }(eCreditMap)
+
+	pub := new([32]byte)
+	eCreditMap = map[string]int32{string(pub[:]): math.MaxInt32 - 10}
+
+	ib := common.NewIncreaseBalance()
+	ib.ECPubKey = pub
+	ib.NumEC = 5
+	ib2 := common.NewIncreaseBalance()
+	ib2.ECPubKey = pub
+	ib2.NumEC = 5
+	ib2.Index = 1
+
+	balances, err := increasedECBalances([]common.ECBlockEntry{ib, ib2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balances[string(pub[:])] != math.MaxInt32 {
+		t.Errorf("balance is %v, expected %v", balances[string(pub[:])], math.MaxInt32)
+	}
+	if eCreditMap[string(pub[:])] != math.MaxInt32-10 {
+		t.Errorf("eCreditMap changed to %v", eCreditMap[string(pub[:])])
+	}
+
+	// one more credit overflows the int32 balance
+	ib2.NumEC = 6
+	if _, err := increasedECBalances([]common.ECBlockEntry{ib, ib2}); err == nil {
+		t.Errorf("balance overflow was not detected")
+	}
+}
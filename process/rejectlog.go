@@ -0,0 +1,67 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"sort"
+	"time"
+)
+
+// rejectLog limits how many rejected messages from peers are logged per message
+// type in each interval, so that a flood of invalid messages cannot flood the
+// log. Errors of the internal control messages are always logged.
+// Rejections past the limit are only counted and reported in one summary line
+// per message type when the interval ends.
+type rejectLog struct {
+	interval time.Duration
+	limit    int
+	start    time.Time
+	counts   map[string]int
+	logf     func(format string, args ...interface{})
+}
+
+var rejectedMsgLog = newRejectLog(time.Minute, 10, procLog.Errorf)
+
+func newRejectLog(interval time.Duration, limit int, logf func(format string, args ...interface{})) *rejectLog {
+	return &rejectLog{
+		interval: interval,
+		limit:    limit,
+		start:    time.Now(),
+		counts:   make(map[string]int),
+		logf:     logf,
+	}
+}
+
+// reject records a rejected message of type cmd and logs err if the limit for
+// the current interval has not been reached yet.
+func (r *rejectLog) reject(cmd string, err error, now time.Time) {
+	r.flush(now)
+	r.counts[cmd]++
+	if r.counts[cmd] <= r.limit {
+		r.logf("%s rejected: %v", cmd, err)
+	}
+}
+
+// flush logs the summary of the messages that were not logged and starts a new
+// interval, once the current interval has ended.
+func (r *rejectLog) flush(now time.Time) {
+	if now.Sub(r.start) < r.interval {
+		return
+	}
+
+	cmds := make([]string, 0, len(r.counts))
+	for cmd := range r.counts {
+		cmds = append(cmds, cmd)
+	}
+	sort.Strings(cmds)
+	for _, cmd := range cmds {
+		if n := r.counts[cmd]; n > r.limit {
+			r.logf("%d %s messages rejected in the last %v, %d not logged", n, cmd, r.interval, n-r.limit)
+		}
+	}
+
+	r.counts = make(map[string]int)
+	r.start = now
+}
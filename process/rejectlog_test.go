@@ -0,0 +1,53 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRejectLog(t *testing.T) {
+	var lines []string
+	logf := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	now := time.Now()
+	r := newRejectLog(time.Minute, 3, logf)
+	r.start = now
+
+	// flood one message type and reject another once
+	for i := 0; i < 100; i++ {
+		r.reject("commitchain", errors.New("invalid"), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	r.reject("revealentry", errors.New("no commit"), now)
+
+	if len(lines) != 4 {
+		t.Fatalf("logged %d lines during the flood, expected 4: %v", len(lines), lines)
+	}
+
+	// nothing is summarized before the interval ends
+	r.flush(now.Add(30 * time.Second))
+	if len(lines) != 4 {
+		t.Errorf("summary logged before the interval ended: %v", lines[4:])
+	}
+
+	r.flush(now.Add(time.Minute))
+	if len(lines) != 5 {
+		t.Fatalf("logged %d lines after the interval, expected 5: %v", len(lines), lines)
+	}
+	if expected := "100 commitchain messages rejected in the last 1m0s, 97 not logged"; lines[4] != expected {
+		t.Errorf("summary is %q, expected %q", lines[4], expected)
+	}
+
+	// a new interval logs again
+	r.reject("commitchain", errors.New("invalid"), now.Add(time.Minute+time.Second))
+	if len(lines) != 6 {
+		t.Errorf("rejection in a new interval was not logged")
+	}
+}
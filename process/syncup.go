@@ -14,9 +14,33 @@ import (
 	"github.com/FactomProject/btcd/wire"
 	"github.com/FactomProject/go-spew/spew"
 	"strconv"
+	"sync"
 	"time"
 )
 
+// blockAcceptor holds the policy for the directory blocks downloaded from
+// peers. The sync retries a rejected block every second or so, so the
+// rejection is only logged once per height.
+type blockAcceptor struct {
+	sync.Mutex
+	policy   func(b *common.DirectoryBlock) error
+	rejected bool
+	height   uint32 // height of the last rejected block
+	logf     func(format string, args ...interface{})
+}
+
+var blockAccept = &blockAcceptor{logf: procLog.Warningf}
+
+// SetBlockAcceptPolicy sets the policy consulted for every directory block
+// downloaded from peers after the block has been validated and before it is
+// stored in db. A non-nil error rejects the block, and the sync stops at its
+// height until the policy accepts it. A nil policy accepts every block.
+func SetBlockAcceptPolicy(policy func(b *common.DirectoryBlock) error) {
+	blockAccept.Lock()
+	defer blockAccept.Unlock()
+	blockAccept.policy = policy
+}
+
 // processDirBlock validates dir block and save it to factom db.
 // similar to blockChain.BC_ProcessBlock
 func processDirBlock(msg *wire.MsgDirBlock) error {
@@ -196,7 +220,7 @@ func validateAndStoreBlocks(fMemPool *ftmMemPool, db database.Db, dchain *common
 			dblk = dchain.Blocks[myDBHeight+1]
 		}
 		if dblk != nil {
-			if validateBlocksFromMemPool(dblk, fMemPool, db) {
+			if validateBlocksFromMemPool(dblk, fMemPool, db) && isBlockAccepted(dblk) {
 				err := storeBlocksFromMemPool(dblk, fMemPool, db)
 				if err == nil {
 					deleteBlocksFromMemPool(dblk, fMemPool)
@@ -229,6 +253,35 @@ func validateAndStoreBlocks(fMemPool *ftmMemPool, db database.Db, dchain *common
 
 }
 
+// isBlockAccepted checks a validated dir block against the block accept policy
+func isBlockAccepted(b *common.DirectoryBlock) bool {
+	return blockAccept.accept(b)
+}
+
+func (a *blockAcceptor) accept(b *common.DirectoryBlock) bool {
+	a.Lock()
+	policy := a.policy
+	a.Unlock()
+	if policy == nil {
+		return true
+	}
+
+	err := policy(b)
+
+	a.Lock()
+	defer a.Unlock()
+	if err == nil {
+		a.rejected = false
+		return true
+	}
+	if !a.rejected || a.height != b.Header.DBHeight {
+		a.logf("Directory block %d rejected by the accept policy: %v", b.Header.DBHeight, err)
+	}
+	a.rejected = true
+	a.height = b.Header.DBHeight
+	return false
+}
+
 // Validate the new blocks in mem pool and store them in db
 func validateBlocksFromMemPool(b *common.DirectoryBlock, fMemPool *ftmMemPool, db database.Db) bool {
 
@@ -249,8 +302,15 @@ func validateBlocksFromMemPool(b *common.DirectoryBlock, fMemPool *ftmMemPool, d
 	for _, dbEntry := range b.DBEntries {
 		switch dbEntry.ChainID.String() {
 		case ecchain.ChainID.String():
-			if _, ok := fMemPool.blockpool[dbEntry.KeyMR.String()]; !ok {
+			if msg, ok := fMemPool.blockpool[dbEntry.KeyMR.String()]; !ok {
 				return false
+			} else {
+				// validate the credits paid by every commit in the ECBlock
+				ecBlkMsg, _ := msg.(*wire.MsgECBlock)
+				if err := ecBlkMsg.ECBlock.ValidateCommitCredits(); err != nil {
+					procLog.Error(err)
+					return false
+				}
 			}
 		case achain.ChainID.String():
 			if msg, ok := fMemPool.blockpool[dbEntry.KeyMR.String()]; !ok {
@@ -305,7 +365,9 @@ func storeBlocksFromMemPool(b *common.DirectoryBlock, fMemPool *ftmMemPool, db d
 				return err
 			}
 			// needs to be improved??
-			initializeECreditMap(ecBlkMsg.ECBlock)
+			if err := initializeECreditMap(ecBlkMsg.ECBlock); err != nil {
+				return err
+			}
 			// for debugging
 			exportECBlock(ecBlkMsg.ECBlock)
 		case achain.ChainID.String():
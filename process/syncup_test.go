@@ -0,0 +1,129 @@
+// Copyright 2015 Factom Foundation
+// Use of this source code is governed by the MIT
+// license that can be found in the LICENSE file.
+
+package process
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/FactomProject/FactomCode/common"
+)
+
+func TestBlockAcceptPolicy(t *testing.T) {
+	defer SetBlockAcceptPolicy(nil)
+
+	b := common.NewDirectoryBlock()
+	b.Header.DBHeight = 5
+
+	if !isBlockAccepted(b) {
+		t.Errorf("block rejected without a policy")
+	}
+
+	SetBlockAcceptPolicy(func(b *common.DirectoryBlock) error {
+		if b.Header.DBHeight == 5 {
+			return fmt.Errorf("height 5 is blocked")
+		}
+		return nil
+	})
+
+	if isBlockAccepted(b) {
+		t.Errorf("block at height 5 accepted by a policy that rejects it")
+	}
+
+	b.Header.DBHeight = 6
+	if !isBlockAccepted(b) {
+		t.Errorf("block at height 6 rejected")
+	}
+}
+
+func TestBlockAcceptorLogsOncePerHeight(t *testing.T) {
+	var logged int
+	a := &blockAcceptor{logf: func(format string, args ...interface{}) { logged++ }}
+	a.policy = func(b *common.DirectoryBlock) error {
+		if b.Header.DBHeight < 10 {
+			return fmt.Errorf("blocked")
+		}
+		return nil
+	}
+
+	b := common.NewDirectoryBlock()
+	b.Header.DBHeight = 5
+	for i := 0; i < 3; i++ {
+		a.accept(b)
+	}
+	if logged != 1 {
+		t.Errorf("rejection of height 5 logged %d times, expected once", logged)
+	}
+
+	b.Header.DBHeight = 6
+	a.accept(b)
+	a.accept(b)
+	if logged != 2 {
+		t.Errorf("rejection of height 6 not logged once, %d logs", logged)
+	}
+
+	// after an accepted block a new rejection is logged again
+	b.Header.DBHeight = 10
+	a.accept(b)
+	b.Header.DBHeight = 6
+	a.accept(b)
+	if logged != 3 {
+		t.Errorf("rejection after an accepted block not logged, %d logs", logged)
+	}
+}
+
+func TestValidateDBSignature(t *testing.T) {
+	savedPubKey := serverPubKey
+	defer func() { serverPubKey = savedPubKey }()
+
+	var leader, other common.PrivateKey
+	if err := leader.GenerateKey(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.GenerateKey(); err != nil {
+		t.Fatal(err)
+	}
+	serverPubKey = leader.Pub
+
+	chain := common.NewDChain()
+	prev := common.NewDirectoryBlock()
+	chain.Blocks = append(chain.Blocks, prev)
+	header, err := prev.Header.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newABlock := func(sig common.Signature) *common.AdminBlock {
+		ab := new(common.AdminBlock)
+		ab.Header = new(common.ABlockHeader)
+		ab.Header.DBHeight = 1
+		ab.AddABEntry(common.NewDBSignatureEntry(common.NewHash(), sig))
+		ab.Header.MessageCount = uint32(len(ab.ABEntries))
+		return ab
+	}
+
+	if !validateDBSignature(newABlock(leader.Sign(header)), chain) {
+		t.Errorf("block signed by the server key was rejected")
+	}
+
+	if validateDBSignature(newABlock(other.Sign(header)), chain) {
+		t.Errorf("block signed by another key was accepted")
+	}
+
+	// signed by another key but claiming the server public key
+	forged := other.Sign(header)
+	forged.Pub = leader.Pub
+	if validateDBSignature(newABlock(forged), chain) {
+		t.Errorf("forged signature was accepted")
+	}
+
+	// a block past the genesis block without any signature
+	ab := new(common.AdminBlock)
+	ab.Header = new(common.ABlockHeader)
+	ab.Header.DBHeight = 1
+	if validateDBSignature(ab, chain) {
+		t.Errorf("unsigned block was accepted")
+	}
+}
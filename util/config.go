@@ -21,6 +21,7 @@ type FactomdConfig struct {
 		ServerPrivKey           string
 		ServerPubKey            string
 		ExchangeRate            uint64
+		SlowMsgInMilliseconds   int
 	}
 	Anchor struct {
 		ServerECKey         string
@@ -95,6 +96,8 @@ NodeMode                            = FULL
 ServerPrivKey                       = 07c0d52cb74f4ca3106d80c4a70488426886bccc6ebc10c6bafb37bf8a65f4c38cee85c62a9e48039d4ac294da97943c2001be1539809ea5f54721f0c5477a0a
 ServerPubKey                        = "0426a802617848d4d16d87830fc521f4d136bb2d0c352850919c2679f189613a"
 ExchangeRate                        = 00666600
+; --------------- Log a warning when a message takes longer than this to process. 0 disables it.
+SlowMsgInMilliseconds               = 0
 
 [anchor]
 ServerECKey							= 397c49e182caa97737c6b394591c614156fbe7998d7bf5d76273961e9fa1edd406ed9e69bfdf85db8aa69820f348d096985bc0b11cc9fc9dcee3b8c68b41dfd5